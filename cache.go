@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Every successful API response is kept in the workspace cache so lessons
+// and course metadata stay available offline, and so screens that read every
+// course, like the dashboard and the course map, don't fetch them all again
+// each time. Cached responses are used for cacheTTL, then fetched again; an
+// older one is only used when the API can't be reached.
+
+// cacheTTL is how long a cached response is used before it is refreshed.
+const cacheTTL = 24 * time.Hour
+
+func cacheDir() string {
+	return filepath.Join(stateDir(), "cache")
+}
+
+func cachePath(url string) string {
+	name := strings.TrimPrefix(url, BASE_API_URL)
+	name = strings.NewReplacer("/", "_", ":", "_", "?", "_", "&", "_").Replace(strings.Trim(name, "/"))
+	return filepath.Join(cacheDir(), name+".json")
+}
+
+func writeCache(url string, body []byte) {
+	if workspace == "" {
+		return
+	}
+	if err := os.MkdirAll(cacheDir(), os.FileMode(cfg.Workspace.DirMode)); err != nil {
+		return
+	}
+	os.WriteFile(cachePath(url), body, os.FileMode(cfg.Workspace.FileMode))
+}
+
+// readCache returns the cached response for url, and whether it is younger
+// than cacheTTL.
+func readCache[T any](url string) (*T, bool) {
+	if workspace == "" {
+		return nil, false
+	}
+	path := cachePath(url)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var response T
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, false
+	}
+	return &response, time.Since(info.ModTime()) < cacheTTL
+}
+
+// cachedRequest is request, but answered from the cache while the cached
+// response is fresh, and from a stale one when the API can't be reached.
+// Errors from the API itself, like a lesson that no longer exists, are
+// returned as they are.
+func cachedRequest[T any](rawURL string) tea.Msg {
+	cached, fresh := readCache[T](rawURL)
+	if fresh {
+		return cached
+	}
+	res := request[T](rawURL)
+	var offline *url.Error
+	if failed, ok := res.(errMsg); ok && errors.As(failed.err, &offline) && cached != nil {
+		return cached
+	}
+	return res
+}
@@ -0,0 +1,555 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+)
+
+// command is a single bootdev-local subcommand with its own flag set.
+type command struct {
+	name  string
+	args  string
+	short string
+	flags *flag.FlagSet
+	run   func(args []string) error
+}
+
+func (c *command) usage() {
+	out := c.flags.Output()
+	fmt.Fprintf(out, "Usage: bootdev-local %s", c.name)
+	if hasFlags(c.flags) {
+		fmt.Fprint(out, " [flags]")
+	}
+	if c.args != "" {
+		fmt.Fprintf(out, " %s", c.args)
+	}
+	fmt.Fprintf(out, "\n\n%s\n", c.short)
+	if hasFlags(c.flags) {
+		fmt.Fprintln(out, "\nFlags:")
+		c.flags.PrintDefaults()
+	}
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	n := 0
+	fs.VisitAll(func(*flag.Flag) { n++ })
+	return n > 0
+}
+
+func newCommand(name, args, short string, run func(fs *flag.FlagSet) func([]string) error) *command {
+	c := &command{
+		name:  name,
+		args:  args,
+		short: short,
+		flags: flag.NewFlagSet(name, flag.ContinueOnError),
+	}
+	c.flags.Usage = c.usage
+	c.flags.String("workspace", "", "Workspace root (default: nearest parent directory containing "+workspaceMarker+")")
+	c.run = run(c.flags)
+	return c
+}
+
+func commands() []*command {
+	return []*command{
+		newCommand("open", "[uuid-or-url]", "Open a lesson or course in the TUI. Without an argument the last lesson is resumed.", openCmd),
+		newCommand("download", "<course-url>", "Download every lesson of a course without opening an editor.", downloadCmd),
+		newCommand("test", "[uuid-or-url]", "Run the checks for a lesson and print the result. Defaults to the last lesson.", testCmd),
+		newCommand("status", "", "Show the current lesson and a progress dashboard.", statusCmd),
+		newCommand("reset", "<uuid-or-url>", "Restore a lesson's starter files and clear its progress, discarding local changes.", resetCmd),
+		newCommand("sync", "", "Retry git pushes that failed and were queued.", syncCmd),
+		newCommand("search", "<query>", "Search the cached lessons, or course titles with -courses.", searchCmd),
+		newCommand("graph", "", "Show the courses of every track as a prerequisite graph.", graphCmd),
+		newCommand("config", "", "Print the resolved configuration.", configCmd),
+		newCommand("completion", "<bash|zsh|fish>", "Print a shell completion script.", completionCmd),
+	}
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands() {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func printUsage() {
+	fmt.Println("Usage: bootdev-local <command> [flags] [args]")
+	fmt.Println("\nRunning without a command resumes the last lesson.")
+	fmt.Println("\nCommands:")
+	for _, c := range commands() {
+		fmt.Printf("  %-12s %s\n", c.name, c.short)
+	}
+	fmt.Println("\nRun 'bootdev-local help <command>' for the flags of a command.")
+}
+
+func runCLI(args []string) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return findCommand("open").run(nil)
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if c := findCommand(args[1]); c != nil {
+				c.flags.SetOutput(os.Stdout)
+				c.usage()
+				return nil
+			}
+			return fmt.Errorf("unknown command %q", args[1])
+		}
+		printUsage()
+		return nil
+	}
+
+	c := findCommand(args[0])
+	if c == nil {
+		// bootdev-local [flags] <uuid-or-url> is kept as a shorthand for open
+		return findCommand("open").run(args)
+	}
+	return c.run(args[1:])
+}
+
+// parseFlags parses args into fs, treating -h as a successful no-op, then
+// resolves the workspace from its -workspace flag and loads its progress.
+func parseFlags(fs *flag.FlagSet, args []string) (bool, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := setupWorkspace(fs.Lookup("workspace").Value.String()); err != nil {
+		return false, err
+	}
+	var err error
+	if progress, err = loadProgress(); err != nil {
+		return false, err
+	}
+	if pushQueue, err = loadPushQueue(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func runTUI(m Model) error {
+	var opts []tea.ProgramOption
+	if cfg.UI.AltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if cfg.UI.Mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	p = tea.NewProgram(m, opts...)
+	model, err := p.Run()
+	if err := tracker.stop(); err != nil {
+		fmt.Printf("Error: failed to save time spent: %v\n", err)
+	}
+	if err != nil {
+		return err
+	}
+	if _, ok := model.(Model); !ok {
+		return errors.New("unexpected model type")
+	}
+	return nil
+}
+
+func openCmd(fs *flag.FlagSet) func([]string) error {
+	codeEditor := fs.String("code-editor", "", "Editor to open code files with (e.g., 'code', 'vim', 'emacs'), overrides editor.code")
+	mdEditor := fs.String("md-editor", "", "Editor to open markdown files with (e.g., 'typora', 'code'), overrides editor.markdown")
+	download := fs.Bool("download", false, "Download every lesson of the course instead of opening it, like the download command")
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		if *download {
+			return downloadCourse(fs)
+		}
+		if *mdEditor != "" {
+			cfg.Editor.Markdown = *mdEditor
+		}
+		if fs.NArg() > 0 {
+			if _, err := parseTarget(fs.Arg(0)); err != nil {
+				return err
+			}
+		}
+		return runTUI(initialModel(fs.Arg(0), cfg.Editor.Markdown, *codeEditor))
+	}
+}
+
+func downloadCmd(fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		return downloadCourse(fs)
+	}
+}
+
+// downloadCourse downloads the course named by the first argument of fs.
+func downloadCourse(fs *flag.FlagSet) error {
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing course url")
+	}
+	if _, err := parseTarget(fs.Arg(0)); err != nil {
+		return err
+	}
+	m := initialModel(fs.Arg(0), "", "")
+	m.download = true
+	return runTUI(m)
+}
+
+// loadLesson fetches the lesson for url, or the last opened lesson when url
+// is empty, without starting the TUI.
+func loadLesson(url string) (Model, error) {
+	if url != "" {
+		t, err := parseTarget(url)
+		if err != nil {
+			return Model{}, err
+		}
+		if t.Kind != TargetLesson {
+			return Model{}, fmt.Errorf("%q is a %s, not a lesson", url, t.Kind)
+		}
+	}
+	m := initialModel(url, "", "")
+	if m.lessonURL == "" {
+		return m, errors.New("no lesson given and no last lesson recorded")
+	}
+	switch res := cachedRequest[Response](m.lessonURL).(type) {
+	case *Response:
+		m.response.Lesson = res.Lesson
+	case errMsg:
+		return m, res.err
+	}
+	return m, nil
+}
+
+func testCmd(fs *flag.FlagSet) func([]string) error {
+	quiet := fs.Bool("q", false, "Only report pass or fail, not the test output")
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		m, err := loadLesson(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		var res tea.Msg
+		switch m.response.Lesson.Type {
+		case "type_code_tests":
+			res = m.testCode()()
+		case "type_code":
+			res = m.CheckOutput()()
+		case "type_text_input":
+			check, err := m.getLessonCheck()
+			if err != nil {
+				return err
+			}
+			m.response.Lesson.LessonDataTextInput.TextInputData = check
+			res = m.CheckInput()()
+		case "type_cli":
+			dir, err := os.ReadFile(filepath.Join(m.lessonDir(), ".dir"))
+			if err != nil {
+				return fmt.Errorf("no directory recorded for %s, run the lesson with 'open' first", m.response.Lesson.Slug)
+			}
+			m.dir.SetValue(string(dir))
+			res = m.CLIChecks()()
+		default:
+			fmt.Printf("%s has nothing to test\n", m.response.Lesson.Slug)
+			return nil
+		}
+
+		switch res := res.(type) {
+		case errMsg:
+			return res.err
+		case hookVetoMsg:
+			return res.err
+		case CLIErr:
+			m.state = CLIFailed
+			m.content = fmt.Sprintf("Ran %s at %s\n%v\n%s", res.cmd, res.dir, res.err, res.stdout)
+		case CLIDoneMsg:
+			m.state = CLIDone
+		case Model:
+			m = res
+		}
+		if err := m.runHooks(stateHooks(m.state)...); err != nil {
+			return err
+		}
+		if err := m.recordProgress(); err != nil {
+			return err
+		}
+
+		if !*quiet {
+			fmt.Println(m.content)
+		}
+		switch m.state {
+		case CodeTestFailed, OutputFail, InputFail, CLIFailed:
+			return fmt.Errorf("%s failed", m.response.Lesson.Slug)
+		}
+		fmt.Printf("✅ %s passed\n", m.response.Lesson.Slug)
+		return nil
+	}
+}
+
+func statusCmd(fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		if m, err := loadLesson(""); err == nil {
+			l := m.response.Lesson
+			lp := progress.Lesson(l.UUID)
+			fmt.Printf("Course:   %s\n", l.CourseTitle)
+			fmt.Printf("Chapter:  %s\n", l.ChapterTitle)
+			fmt.Printf("Lesson:   %s\n", l.Slug)
+			fmt.Printf("Path:     %s\n", m.lessonDir())
+			fmt.Printf("Status:   %s\n", lp.Status)
+			fmt.Printf("Attempts: %d\n", lp.Attempts)
+			fmt.Printf("Time:     %s", formatDuration(lp.ActiveTime))
+			if course, ok := m.fetchCourse().(courseMsg); ok {
+				m.response.Course = course.response.Course
+				fmt.Printf(" (est. %s)", formatDuration(m.lessonEstimate()))
+			}
+			fmt.Print("\n\n")
+		}
+
+		width, _, err := term.GetSize(os.Stdout.Fd())
+		if err != nil {
+			width = 80
+		}
+		fmt.Print(loadStats().render(width))
+		return nil
+	}
+}
+
+func resetCmd(fs *flag.FlagSet) func([]string) error {
+	yes := fs.Bool("y", false, "Do not ask for confirmation")
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		if fs.NArg() == 0 {
+			fs.Usage()
+			return errors.New("missing lesson")
+		}
+		m, err := loadLesson(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if !*yes && !confirm(fmt.Sprintf("Reset %s to its starter files?", m.lessonDir())) {
+			return nil
+		}
+
+		starterFiles, _, err := m.lessonFiles()
+		if err != nil {
+			return err
+		}
+		for _, file := range starterFiles {
+			if err := os.Remove(filepath.Join(m.lessonDir(), file.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		m.download = true
+		if res, ok := m.createCodeFiles()().(errMsg); ok {
+			return res.err
+		}
+		if err := progress.Reset(m.response.Lesson.UUID); err != nil {
+			return err
+		}
+		fmt.Printf("Reset %s\n", m.lessonDir())
+		return nil
+	}
+}
+
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func syncCmd(fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		if pushQueue.Len() == 0 {
+			fmt.Println("Nothing to push")
+			return nil
+		}
+		pending, err := pushQueue.Flush(os.Stdout, true)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d pushes still pending", pending)
+		}
+		fmt.Println("✅ All pushes synced")
+		return nil
+	}
+}
+
+func searchCmd(fs *flag.FlagSet) func([]string) error {
+	courses := fs.Bool("courses", false, "Search course titles across all tracks instead of lessons")
+	limit := fs.Int("n", 20, "Show at most this many lessons")
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		if fs.NArg() == 0 {
+			fs.Usage()
+			return errors.New("missing query")
+		}
+		query := strings.ToLower(strings.Join(fs.Args(), " "))
+		if *courses {
+			return searchCourses(query)
+		}
+
+		ix, err := loadSearchIndex()
+		if err != nil {
+			return fmt.Errorf("failed to index lessons: %v", err)
+		}
+		if len(ix.docs) == 0 {
+			fmt.Println("No cached lessons, open or download some first")
+			return nil
+		}
+		results := ix.search(query, *limit)
+		if len(results) == 0 {
+			fmt.Printf("No lessons match %q\n", query)
+		}
+		for _, r := range results {
+			fmt.Printf("%s  (%s › %s)\n  %s\n", r.doc.Title, r.doc.Course, r.doc.Chapter, r.doc.UUID)
+			if r.snippet != "" {
+				fmt.Printf("  %s\n", r.snippet)
+			}
+		}
+		return nil
+	}
+}
+
+func searchCourses(query string) error {
+	var tracks *TracksResponse
+	switch res := request[TracksResponse](TRACKS_URL).(type) {
+	case *TracksResponse:
+		tracks = res
+	case errMsg:
+		return res.err
+	}
+
+	found := 0
+	for _, track := range *tracks {
+		for _, course := range track.Courses {
+			if strings.Contains(strings.ToLower(course.Title), query) {
+				fmt.Printf("%-40s %s  (%s)\n", course.Title, course.FirstLessonUUID, track.Title)
+				found++
+			}
+		}
+	}
+	if found == 0 {
+		fmt.Printf("No courses match %q\n", query)
+	}
+	return nil
+}
+
+func graphCmd(fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		g, err := loadCourseGraph()
+		if err != nil {
+			return err
+		}
+		fmt.Print(g.render(""))
+		return nil
+	}
+}
+
+func configCmd(fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		fmt.Printf("# %s\n", cfgPath)
+		return cfg.write(os.Stdout)
+	}
+}
+
+func completionCmd(fs *flag.FlagSet) func([]string) error {
+	return func(args []string) error {
+		if ok, err := parseFlags(fs, args); !ok {
+			return err
+		}
+		cmds := commands()
+		names := make([]string, 0, len(cmds))
+		flags := map[string][]string{}
+		for _, c := range cmds {
+			names = append(names, c.name)
+			c.flags.VisitAll(func(f *flag.Flag) {
+				flags[c.name] = append(flags[c.name], "-"+f.Name)
+			})
+		}
+		sort.Strings(names)
+
+		switch fs.Arg(0) {
+		case "bash":
+			fmt.Print(bashCompletion(names, flags))
+		case "zsh":
+			fmt.Println("autoload -U +X bashcompinit && bashcompinit")
+			fmt.Print(bashCompletion(names, flags))
+		case "fish":
+			fmt.Print(fishCompletion(cmds))
+		default:
+			fs.Usage()
+			return fmt.Errorf("unsupported shell %q", fs.Arg(0))
+		}
+		return nil
+	}
+}
+
+func bashCompletion(names []string, flags map[string][]string) string {
+	var b strings.Builder
+	b.WriteString("_bootdev_local() {\n")
+	b.WriteString("  local cur=${COMP_WORDS[COMP_CWORD]}\n")
+	b.WriteString("  if [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(&b, "    COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	b.WriteString("    return\n  fi\n")
+	b.WriteString("  case ${COMP_WORDS[1]} in\n")
+	for _, name := range names {
+		if len(flags[name]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "    %s) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", name, strings.Join(flags[name], " "))
+	}
+	b.WriteString("    completion) COMPREPLY=($(compgen -W \"bash zsh fish\" -- \"$cur\")) ;;\n")
+	b.WriteString("  esac\n}\n")
+	b.WriteString("complete -o default -F _bootdev_local bootdev-local\n")
+	return b.String()
+}
+
+func fishCompletion(cmds []*command) string {
+	var b strings.Builder
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "complete -c bootdev-local -n __fish_use_subcommand -a %s -d %q\n", c.name, c.short)
+		c.flags.VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(&b, "complete -c bootdev-local -n '__fish_seen_subcommand_from %s' -o %s -d %q\n", c.name, f.Name, f.Usage)
+		})
+	}
+	b.WriteString("complete -c bootdev-local -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n")
+	return b.String()
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config is read from $XDG_CONFIG_HOME/bootdev-local/config.toml. Command
+// line flags take precedence over anything set here.
+type Config struct {
+	Editor    EditorConfig            `toml:"editor"`
+	Workspace WorkspaceConfig         `toml:"workspace"`
+	Runners   map[string]RunnerConfig `toml:"runners"`
+	Git       GitConfig               `toml:"git"`
+	Quiz      QuizConfig              `toml:"quiz"`
+	UI        UIConfig                `toml:"ui"`
+	// Hooks maps a lifecycle event to a shell command, see hookEvents.
+	Hooks map[string]string `toml:"hooks"`
+	// Keys binds TUI actions to other keys, e.g. quit = ["q", "ctrl+q"].
+	Keys map[string][]string `toml:"keys"`
+}
+
+type EditorConfig struct {
+	// Code and Markdown are full commands, e.g. "code --wait". An empty Code
+	// falls back to $VISUAL, $EDITOR and then nvr.
+	Code     string `toml:"code"`
+	Markdown string `toml:"markdown"`
+	// Languages overrides Code per lesson ProgLang (e.g. "go", "py", "c").
+	Languages map[string]string `toml:"languages"`
+	// Multiplexer opens the editor in tmux or zellij panes instead of
+	// suspending the TUI: "tmux", "zellij", "auto" or empty for off.
+	Multiplexer string `toml:"multiplexer"`
+}
+
+type WorkspaceConfig struct {
+	Root     string   `toml:"root"`
+	FileMode fileMode `toml:"file_mode"`
+	DirMode  fileMode `toml:"dir_mode"`
+}
+
+// fileMode is written in the config as an octal string such as "0644".
+type fileMode os.FileMode
+
+func (f fileMode) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%#o", uint32(f))), nil
+}
+
+func (f *fileMode) UnmarshalText(text []byte) error {
+	n, err := strconv.ParseUint(strings.TrimPrefix(string(text), "0o"), 8, 32)
+	if err != nil {
+		return fmt.Errorf("invalid file mode %q", text)
+	}
+	*f = fileMode(n)
+	return nil
+}
+
+// RunnerConfig replaces the .lib/<lang> Makefile and run script for a
+// language. ${lesson} and ${lang} are substituted before running with sh -c.
+type RunnerConfig struct {
+	Test string `toml:"test"`
+	Run  string `toml:"run"`
+}
+
+// GitConfig controls what happens after a lesson is completed. Message is a
+// text/template, see commitData for the available fields.
+type GitConfig struct {
+	Mode            string `toml:"mode"`
+	Message         string `toml:"message"`
+	Remote          string `toml:"remote"`
+	BranchPerCourse bool   `toml:"branch_per_course"`
+	TagChapters     bool   `toml:"tag_chapters"`
+	TagCourses      bool   `toml:"tag_courses"`
+}
+
+type QuizConfig struct {
+	// Attempts is the number of answers allowed per quiz, 0 for no limit.
+	Attempts int `toml:"attempts"`
+	// Exam allows a single answer and hides the lesson text until then.
+	Exam bool `toml:"exam"`
+	// Shuffle shows the answers in a random order.
+	Shuffle bool `toml:"shuffle"`
+}
+
+type UIConfig struct {
+	AltScreen bool `toml:"alt_screen"`
+	Mouse     bool `toml:"mouse"`
+	// MarkdownStyle is a glamour style: dark, light, dracula, notty, ...
+	MarkdownStyle string `toml:"markdown_style"`
+	// Layout is "split" to show the README next to check output on wide
+	// terminals, or "single".
+	Layout string `toml:"layout"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Editor: EditorConfig{Languages: map[string]string{}},
+		Workspace: WorkspaceConfig{
+			FileMode: 0o644,
+			DirMode:  0o755,
+		},
+		Runners: map[string]RunnerConfig{},
+		Git:     GitConfig{Mode: GitPush, Message: defaultCommitMessage, Remote: "origin"},
+		Quiz:    QuizConfig{Attempts: 3},
+		UI:      UIConfig{AltScreen: true, Mouse: true, MarkdownStyle: "dark", Layout: "split"},
+		Hooks:   map[string]string{},
+	}
+}
+
+var (
+	cfg     = defaultConfig()
+	cfgPath string
+)
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bootdev-local", "config.toml"), nil
+}
+
+// loadConfig reads the config file over the defaults. A missing file is not
+// an error.
+func loadConfig() error {
+	var err error
+	if cfgPath, err = configPath(); err != nil {
+		return nil
+	}
+	if _, err := toml.DecodeFile(cfgPath, &cfg); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config %s: %v", cfgPath, err)
+	}
+	if cfg.Quiz.Attempts < 0 {
+		return fmt.Errorf("%s: quiz.attempts must be 0 for no limit or more", cfgPath)
+	}
+	for event := range cfg.Hooks {
+		if !slices.Contains(hookEvents, event) {
+			return fmt.Errorf("%s: unknown hook %q, must be one of %s", cfgPath, event, strings.Join(hookEvents, ", "))
+		}
+	}
+	if err := keys.remap(cfg.Keys); err != nil {
+		return fmt.Errorf("%s: %v", cfgPath, err)
+	}
+	if cfg.UI.Layout != "split" && cfg.UI.Layout != "single" {
+		return fmt.Errorf("%s: ui.layout must be split or single", cfgPath)
+	}
+	switch cfg.Editor.Multiplexer {
+	case "", "auto", "tmux", "zellij":
+	default:
+		return fmt.Errorf("%s: editor.multiplexer must be one of auto, tmux or zellij", cfgPath)
+	}
+	switch cfg.Git.Mode {
+	case GitOff, GitCommit, GitPush:
+	default:
+		return fmt.Errorf("%s: git.mode must be one of off, commit or push", cfgPath)
+	}
+	cfg.Workspace.Root = expandHome(cfg.Workspace.Root)
+	return nil
+}
+
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[1:])
+}
+
+func (c Config) write(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(c)
+}
+
+// runner returns the configured runner command for lang and kind ("test" or
+// "run"), or "" when the .lib default should be used.
+func (c Config) runner(lang, kind, lessonPath string) string {
+	r, ok := c.Runners[lang]
+	if !ok {
+		return ""
+	}
+	command := r.Test
+	if kind == "run" {
+		command = r.Run
+	}
+	if command == "" {
+		return ""
+	}
+	return InterpolateVariables(command, map[string]string{"lesson": lessonPath, "lang": lang})
+}
+
+// editorCommand splits a configured editor command into name and arguments.
+func editorCommand(command string) (string, []string) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], fields[1:]
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// trackCourses returns every course listed in the tracks, by UUID.
+func trackCourses() map[string]Course {
+	courses := map[string]Course{}
+	if tracks, ok := cachedRequest[TracksResponse](TRACKS_URL).(*TracksResponse); ok {
+		for _, track := range *tracks {
+			for _, course := range track.Courses {
+				courses[course.UUID] = course
+			}
+		}
+	}
+	return courses
+}
+
+// coursePassed counts the lessons of a course passed locally.
+func coursePassed(uuid string) int {
+	passed := 0
+	if progress == nil {
+		return 0
+	}
+	for _, l := range progress.All() {
+		if l.CourseUUID == uuid && l.Status == StatusPassed {
+			passed++
+		}
+	}
+	return passed
+}
+
+type prerequisite struct {
+	UUID   string
+	Slug   string
+	Title  string
+	Passed int
+	Total  int
+}
+
+func (p prerequisite) done() bool {
+	return p.Total > 0 && p.Passed >= p.Total
+}
+
+// courseOverview is the course screen shown before its chapters.
+type courseOverview struct {
+	course  CourseDetails
+	prereqs []prerequisite
+	details viewport.Model
+}
+
+type courseOverviewMsg struct {
+	overview courseOverview
+}
+
+// fetchOverview fetches the course slug and the prerequisites' lesson counts.
+func fetchOverview(slug string) tea.Cmd {
+	return func() tea.Msg {
+		var course CourseDetails
+		switch res := cachedRequest[Response](COURSE_URL + slug).(type) {
+		case *Response:
+			course = res.Course
+		case errMsg:
+			return res
+		}
+		courses := trackCourses()
+		overview := courseOverview{course: course}
+		for _, uuid := range course.PrerequisiteCourseUUIDS {
+			p := prerequisite{UUID: uuid, Title: uuid, Passed: coursePassed(uuid)}
+			if c, ok := courses[uuid]; ok {
+				p.Slug, p.Title = c.Slug, c.Title
+			}
+			if p.Slug != "" {
+				if res, ok := cachedRequest[Response](COURSE_URL + p.Slug).(*Response); ok {
+					p.Total = res.Course.NumLessons
+				}
+			}
+			overview.prereqs = append(overview.prereqs, p)
+		}
+		return courseOverviewMsg{overview: overview}
+	}
+}
+
+// render formats the course's metadata as markdown.
+func (o courseOverview) render() string {
+	c := o.course
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", c.Title)
+	if c.ShortDescription != "" {
+		fmt.Fprintf(&b, "*%s*\n\n", c.ShortDescription)
+	}
+
+	var facts []string
+	if c.NumLessons > 0 {
+		facts = append(facts, fmt.Sprintf("%d lessons", c.NumLessons))
+	}
+	if c.EstimatedCompletionTimeHours > 0 {
+		facts = append(facts, fmt.Sprintf("about %d hours", c.EstimatedCompletionTimeHours))
+	}
+	if c.Language != "" {
+		facts = append(facts, c.Language)
+	}
+	if c.Rating.TotalCount > 0 {
+		facts = append(facts, fmt.Sprintf("★ %.1f (%d ratings)", c.Rating.Average, c.Rating.TotalCount))
+	}
+	if c.NumEnrolled > 0 {
+		facts = append(facts, fmt.Sprintf("%d enrolled", c.NumEnrolled))
+	}
+	if c.CompletionXp > 0 {
+		facts = append(facts, fmt.Sprintf("%d XP", c.CompletionXp))
+	}
+	if len(facts) > 0 {
+		fmt.Fprintf(&b, "%s\n\n", strings.Join(facts, " · "))
+	}
+	if passed := coursePassed(c.UUID); passed > 0 && c.NumLessons > 0 {
+		fmt.Fprintf(&b, "You have passed %d of %d lessons.\n\n", passed, c.NumLessons)
+	}
+	if c.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", c.Description)
+	}
+
+	teachers := func(heading string, people []Teacher) {
+		if len(people) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "## %s\n\n", heading)
+		for _, t := range people {
+			fmt.Fprintf(&b, "- **%s %s**", t.FirstName, t.LastName)
+			if t.Subtitle != "" {
+				fmt.Fprintf(&b, ", %s", t.Subtitle)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	teachers("Authors", c.Teachers.Authors)
+	teachers("Maintainers", c.Teachers.Maintainers)
+
+	if len(c.RecommendedCommunities) > 0 {
+		b.WriteString("## Communities\n\n")
+		for _, community := range c.RecommendedCommunities {
+			fmt.Fprintf(&b, "- [%s](%s)\n", community.Name, community.Link)
+		}
+	}
+	return b.String()
+}
+
+// overviewList offers the course's chapters and its prerequisites.
+func (m Model) overviewList() list.Model {
+	items := []list.Item{item{title: "Open chapters", marker: "▶"}}
+	for _, p := range m.overview.prereqs {
+		status := StatusNotStarted
+		switch {
+		case p.done():
+			status = StatusPassed
+		case p.Passed > 0:
+			status = StatusAttempted
+		}
+		title := "Prerequisite: " + p.Title
+		if p.Total > 0 {
+			title += fmt.Sprintf(" (%d/%d)", p.Passed, p.Total)
+		}
+		items = append(items, item{title: title, marker: statusMarker(status)})
+	}
+	l := m.newList(items, ItemDelegate{})
+	l.Title = "Start the course or a prerequisite"
+	l.SetFilteringEnabled(false)
+	return l
+}
+
+// layoutOverview renders the course details above the list, leaving the list
+// room for its items.
+func (m *Model) layoutOverview() {
+	listHeight := len(m.list.Items())*ItemDelegate{}.Height() + 6
+	rendered := renderMarkdown(m.overview.render(), m.width)
+	height := min(lipgloss.Height(rendered), max(3, m.height-listHeight))
+	m.overview.details = viewport.New(m.width, height)
+	m.overview.details.SetContent(rendered)
+	m.list.SetSize(m.width, m.height-height)
+}
+
+func (m *Model) showOverview(overview courseOverview) {
+	m.overview = overview
+	m.response.Course = overview.course
+	m.state = CourseOverview
+	m.list = m.overviewList()
+	m.layoutOverview()
+}
+
+// openOverviewItem opens the chapters, or the overview of the selected
+// prerequisite.
+func (m *Model) openOverviewItem() tea.Cmd {
+	i := index(m.list)
+	if i == 0 {
+		m.lessonURL, m.courseURL = "", ""
+		m.courseProgressURL = COURSE_PROGRESS_URL + m.overview.course.FirstLessonUUID
+		m.state = Fetch
+		return m.fetchLesson
+	}
+	p := m.overview.prereqs[i-1]
+	if p.Slug == "" {
+		return nil
+	}
+	m.state = Fetch
+	return fetchOverview(p.Slug)
+}
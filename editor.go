@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorProfile knows how to open a lesson's README next to its code files
+// in a particular editor, in a way that blocks until the editor is closed so
+// the lesson can be checked afterwards. readme is empty when the README is
+// opened in a separate markdown editor.
+type editorProfile func(readme string, files []string, m Model) []string
+
+var editorProfiles = map[string]editorProfile{
+	"vim":  vimProfile,
+	"nvim": nvimProfile,
+	"nvr":  nvrProfile,
+	"code": func(readme string, files []string, m Model) []string {
+		return append([]string{"--wait", "--new-window"}, withReadme(readme, files)...)
+	},
+	"codium": func(readme string, files []string, m Model) []string {
+		return append([]string{"--wait", "--new-window"}, withReadme(readme, files)...)
+	},
+	"emacsclient": func(readme string, files []string, m Model) []string {
+		// emacsclient waits until every file has been marked done with C-x #
+		return append([]string{"-c"}, withReadme(readme, files)...)
+	},
+	"hx": func(readme string, files []string, m Model) []string {
+		return append([]string{"--vsplit"}, withReadme(readme, files)...)
+	},
+	"helix": func(readme string, files []string, m Model) []string {
+		return append([]string{"--vsplit"}, withReadme(readme, files)...)
+	},
+	"nano": func(readme string, files []string, m Model) []string {
+		// nano has no splits, the files are opened as buffers
+		return withReadme(readme, files)
+	},
+	"zed": func(readme string, files []string, m Model) []string {
+		return append([]string{"--wait", "--new"}, withReadme(readme, files)...)
+	},
+	"subl": func(readme string, files []string, m Model) []string {
+		return append([]string{"--wait", "--new-window"}, withReadme(readme, files)...)
+	},
+}
+
+func withReadme(readme string, files []string) []string {
+	if readme == "" {
+		return files
+	}
+	return append([]string{readme}, files...)
+}
+
+// nonCodeLesson reports whether the lesson has no code to edit, which the
+// bootdev Neovim plugin uses to skip running tests on save.
+func (m Model) nonCodeLesson() bool {
+	switch m.response.Lesson.Type {
+	case "type_cli", "type_manual", "type_text_input":
+		return true
+	}
+	return false
+}
+
+func vimProfile(readme string, files []string, m Model) []string {
+	return append([]string{"-O"}, withReadme(readme, files)...)
+}
+
+func nvimProfile(readme string, files []string, m Model) []string {
+	args := vimProfile(readme, files, m)
+	if m.nonCodeLesson() {
+		args = append(args, "-c", "lua vim.g.bootdev=true")
+	}
+	return args
+}
+
+// nvrProfile opens the files in the Neovim instance the TUI runs in, with
+// the README rendered by glow in a terminal split.
+func nvrProfile(readme string, files []string, m Model) []string {
+	args := slices.Clone(files)
+	if readme != "" {
+		args = append(args, "-cc", fmt.Sprintf("terminal glow -p %s", readme), "-cc", "vsplit")
+	}
+	args = append(args, "--remote-wait-silent")
+	if m.nonCodeLesson() {
+		args = append(args, "-cc", "lua vim.g.bootdev=true")
+	}
+	return args
+}
+
+// editorFor resolves the code editor command: the -code-editor flag, the
+// language's editor, editor.code, $VISUAL, $EDITOR and finally nvr.
+func (m Model) editorFor() string {
+	for _, command := range []string{
+		m.codeEditor,
+		cfg.Editor.Languages[m.progLang()],
+		cfg.Editor.Code,
+		os.Getenv("VISUAL"),
+		os.Getenv("EDITOR"),
+	} {
+		if command != "" {
+			return command
+		}
+	}
+	return "nvr"
+}
+
+// editorArgs builds the arguments to open the lesson with editor. Editors
+// without a profile get the code files, or the README if there are none.
+func (m Model) editorArgs(editor string, args []string, readme string) []string {
+	files := m.starterFiles[1:]
+	if profile, ok := editorProfiles[filepath.Base(editor)]; ok {
+		return append(args, profile(readme, files, m)...)
+	}
+	if len(files) == 0 && readme != "" {
+		return append(args, readme)
+	}
+	return append(args, files...)
+}
+
+// openMarkdownEditor opens the README in the markdown editor without
+// waiting for it.
+func (m Model) openMarkdownEditor() error {
+	name, args := editorCommand(m.mdEditor)
+	cmd := exec.Command(name, append(args, m.starterFiles[0])...)
+	cmd.Dir = m.lessonDir()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open markdown editor: %v", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// openEditor opens the lesson in panes of a terminal multiplexer when one
+// is configured, otherwise it suspends the TUI until the editor exits.
+func (m Model) openEditor() tea.Cmd {
+	if mux := detectMultiplexer(); mux != nil {
+		return m.openPanes(mux)
+	}
+
+	readme := m.starterFiles[0]
+	if m.mdEditor != "" {
+		if err := m.openMarkdownEditor(); err != nil {
+			return func() tea.Msg { return errMsg{err: err} }
+		}
+		readme = ""
+	}
+
+	codeEditor, args := editorCommand(m.editorFor())
+	cmd := exec.Command(codeEditor, m.editorArgs(codeEditor, args, readme)...)
+	cmd.Dir = m.lessonDir()
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return errMsg{
+				err: fmt.Errorf("failed to open editor: %s\nArgs: %v", err, m.starterFiles),
+			}
+		}
+		m.state = EditorFinished
+		return m
+	})
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	GitOff    = "off"
+	GitCommit = "commit"
+	GitPush   = "push"
+)
+
+const defaultCommitMessage = "{{.Course}} - Chapter {{.Chapter}} - Lesson {{.Lesson}}"
+
+// commitData is available to the git.message template. Chapter and Lesson
+// are 0 when the course does not list the lesson.
+type commitData struct {
+	Course       string
+	CourseTitle  string
+	Chapter      int
+	ChapterTitle string
+	Lesson       int
+	Slug         string
+	Title        string
+	Type         string
+	Attempts     int
+	Result       LessonStatus
+}
+
+func (m Model) commitMessage() (string, error) {
+	tmpl, err := template.New("message").Parse(cfg.Git.Message)
+	if err != nil {
+		return "", fmt.Errorf("invalid git.message: %v", err)
+	}
+	lesson := m.response.Lesson
+	lp := progress.Lesson(lesson.UUID)
+	chapter, number, _ := m.lessonPosition()
+	var b strings.Builder
+	err = tmpl.Execute(&b, commitData{
+		Course:       lesson.CourseSlug,
+		CourseTitle:  lesson.CourseTitle,
+		Chapter:      chapter,
+		ChapterTitle: lesson.ChapterTitle,
+		Lesson:       number,
+		Slug:         lesson.Slug,
+		Title:        lesson.Title,
+		Type:         strings.TrimPrefix(lesson.Type, "type_"),
+		Attempts:     lp.Attempts,
+		Result:       lp.Status,
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid git.message: %v", err)
+	}
+	return b.String(), nil
+}
+
+func gitCmd(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workspace
+	return cmd
+}
+
+// gitSteps builds the steps that commit, tag and push the lesson. An
+// unchanged lesson is not committed and moving an existing tag does not stop
+// the push, and a failed push is queued instead of stopping the lesson flow.
+// Without a remote, as right after git init, there is nothing to push to.
+func (m Model) gitSteps() ([]Step, error) {
+	var steps []Step
+
+	branch := m.response.Lesson.CourseSlug
+	if cfg.Git.BranchPerCourse {
+		current, _ := gitCmd("rev-parse", "--abbrev-ref", "HEAD").Output()
+		if strings.TrimSpace(string(current)) != branch {
+			if gitCmd("show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil {
+				steps = append(steps, cmdStep(gitCmd("switch", branch)))
+			} else {
+				steps = append(steps, cmdStep(gitCmd("switch", "-c", branch)))
+			}
+		}
+	}
+
+	msg, err := m.commitMessage()
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, cmdStep(gitCmd("add", m.lessonPath())))
+	steps = append(steps, commitStep(msg))
+	for _, tag := range m.completionTags() {
+		step := cmdStep(gitCmd("tag", "-f", "-a", tag[0], "-m", tag[1]))
+		step.ContinueOnError = true
+		steps = append(steps, step)
+	}
+
+	if cfg.Git.Mode == GitPush && !hasRemote() {
+		// a push could never succeed, so it is not queued either
+		steps = append(steps, Step{Name: "git push", Run: func(w io.Writer) error {
+			fmt.Fprintln(w, "no git remote to push to, add one with git remote add")
+			return nil
+		}})
+	} else if cfg.Git.Mode == GitPush {
+		args := []string{"push", "--follow-tags"}
+		if cfg.Git.BranchPerCourse {
+			args = append(args, "-u", cfg.Git.Remote, branch)
+		}
+		if pid, err := GetTracerPid(); err == nil && pid > 0 {
+			args = append(args, "-n", "-v")
+		}
+		steps = append(steps, pushStep(args))
+	}
+	return steps, nil
+}
+
+// hasRemote reports whether the workspace has a remote to push to: the
+// configured one for branch-per-course pushes, else any.
+func hasRemote() bool {
+	if cfg.Git.BranchPerCourse {
+		return gitCmd("remote", "get-url", cfg.Git.Remote).Run() == nil
+	}
+	out, err := gitCmd("remote").Output()
+	return err == nil && strings.TrimSpace(string(out)) != ""
+}
+
+// commitStep commits what is staged, and skips the commit when nothing is,
+// as happens when a passed lesson is run again. Any other failure stops the
+// pipeline.
+func commitStep(msg string) Step {
+	step := cmdStep(gitCmd("commit", "-m", msg))
+	commit := step.Run
+	step.Run = func(w io.Writer) error {
+		err := gitCmd("diff", "--cached", "--quiet").Run()
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			fmt.Fprintln(w, "nothing to commit")
+			return nil
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+			return commit(w)
+		}
+		return fmt.Errorf("failed to check for staged changes: %v", err)
+	}
+	return step
+}
+
+// completionTags returns the name and message of the tags to create when
+// the current lesson completes its chapter or course.
+func (m Model) completionTags() [][2]string {
+	if !cfg.Git.TagChapters && !cfg.Git.TagCourses {
+		return nil
+	}
+	course := m.courseProgressResponse
+	if course == nil {
+		res, ok := cachedRequest[CourseProgressResponse](COURSE_PROGRESS_URL + m.response.Lesson.UUID).(*CourseProgressResponse)
+		if !ok {
+			return nil
+		}
+		course = res
+	}
+
+	lesson := m.response.Lesson
+	var tags [][2]string
+	courseDone := true
+	for _, chap := range course.Chapters {
+		done := true
+		current := false
+		for _, l := range chap.Lessons {
+			current = current || l.UUID == lesson.UUID
+			done = done && progress.Lesson(l.UUID).Status == StatusPassed
+		}
+		courseDone = courseDone && done
+		if current && done && cfg.Git.TagChapters {
+			tags = append(tags, [2]string{
+				lesson.CourseSlug + "/" + lesson.ChapterSlug,
+				fmt.Sprintf("Completed %s: %s", lesson.CourseTitle, lesson.ChapterTitle),
+			})
+		}
+	}
+	if courseDone && cfg.Git.TagCourses {
+		tags = append(tags, [2]string{
+			lesson.CourseSlug + "/complete",
+			fmt.Sprintf("Completed %s", lesson.CourseTitle),
+		})
+	}
+	return tags
+}
+
+func (m Model) commitRepo() tea.Cmd {
+	return func() tea.Msg {
+		if cfg.Git.Mode == GitOff {
+			return m.getNextLesson()()
+		}
+		if _, err := os.Stat(workspacePath(".git")); err != nil {
+			m.state = GitInit
+			return m
+		}
+
+		steps, err := m.gitSteps()
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return Pipeline{Name: "git", Steps: steps}
+	}
+}
+
+// initRepo runs git init in the workspace before committing the lesson.
+func (m Model) initRepo() tea.Cmd {
+	return func() tea.Msg {
+		steps, err := m.gitSteps()
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return Pipeline{Name: "git", Steps: append([]Step{cmdStep(gitCmd("init"))}, steps...)}
+	}
+}
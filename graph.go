@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// courseGraph is the prerequisite graph of the courses of every track.
+type courseGraph struct {
+	courses map[string]Course
+	// order lists the courses so each one comes after its prerequisites,
+	// otherwise keeping the order of the tracks.
+	order   []string
+	prereqs map[string][]string
+	lessons map[string]int
+}
+
+type courseGraphMsg struct {
+	graph *courseGraph
+	// show opens the graph screen, rather than only ordering the courses.
+	show bool
+}
+
+// loadCourseGraph builds the graph from the tracks and the course details,
+// which are read from the cache while it is fresh. Prerequisites outside
+// the tracks are left out.
+func loadCourseGraph() (*courseGraph, error) {
+	var tracks *TracksResponse
+	switch res := cachedRequest[TracksResponse](TRACKS_URL).(type) {
+	case *TracksResponse:
+		tracks = res
+	case errMsg:
+		return nil, res.err
+	}
+	g := &courseGraph{courses: map[string]Course{}, prereqs: map[string][]string{}, lessons: map[string]int{}}
+	var uuids []string
+	for _, track := range *tracks {
+		for _, course := range track.Courses {
+			if _, ok := g.courses[course.UUID]; !ok {
+				g.courses[course.UUID] = course
+				uuids = append(uuids, course.UUID)
+			}
+		}
+	}
+	for _, uuid := range uuids {
+		slug := g.courses[uuid].Slug
+		if slug == "" {
+			continue
+		}
+		res, ok := cachedRequest[Response](COURSE_URL + slug).(*Response)
+		if !ok {
+			continue
+		}
+		g.lessons[uuid] = res.Course.NumLessons
+		for _, prereq := range res.Course.PrerequisiteCourseUUIDS {
+			if _, ok := g.courses[prereq]; ok && prereq != uuid {
+				g.prereqs[uuid] = append(g.prereqs[uuid], prereq)
+			}
+		}
+	}
+
+	// Kahn's algorithm, always taking the earliest course that is ready. A
+	// cycle is broken at its earliest course.
+	placed := map[string]bool{}
+	for len(g.order) < len(uuids) {
+		next := ""
+		for _, uuid := range uuids {
+			if placed[uuid] {
+				continue
+			}
+			if next == "" {
+				next = uuid
+			}
+			ready := true
+			for _, prereq := range g.prereqs[uuid] {
+				ready = ready && placed[prereq]
+			}
+			if ready {
+				next = uuid
+				break
+			}
+		}
+		placed[next] = true
+		g.order = append(g.order, next)
+	}
+	return g, nil
+}
+
+func fetchCourseGraph(show bool) tea.Cmd {
+	return func() tea.Msg {
+		g, err := loadCourseGraph()
+		if err != nil {
+			if show {
+				return errMsg{err: err}
+			}
+			return nil
+		}
+		return courseGraphMsg{graph: g, show: show}
+	}
+}
+
+// sort orders courses so each one comes after its prerequisites.
+func (g *courseGraph) sort(courses []Course) {
+	rank := map[string]int{}
+	for i, uuid := range g.order {
+		rank[uuid] = i
+	}
+	sort.SliceStable(courses, func(i, j int) bool {
+		ri, ok := rank[courses[i].UUID]
+		if !ok {
+			return false
+		}
+		rj, ok := rank[courses[j].UUID]
+		return !ok || ri < rj
+	})
+}
+
+func (g *courseGraph) status(uuid string) LessonStatus {
+	passed := coursePassed(uuid)
+	switch {
+	case g.lessons[uuid] > 0 && passed >= g.lessons[uuid]:
+		return StatusPassed
+	case passed > 0:
+		return StatusAttempted
+	}
+	return StatusNotStarted
+}
+
+// ready reports whether every prerequisite of the course is finished.
+func (g *courseGraph) ready(uuid string) bool {
+	for _, prereq := range g.prereqs[uuid] {
+		if g.status(prereq) != StatusPassed {
+			return false
+		}
+	}
+	return true
+}
+
+// suggest picks the course to take after finished: the first unfinished
+// course whose prerequisites are all done, preferring the courses that build
+// on finished.
+func (g *courseGraph) suggest(finished string) (Course, bool) {
+	var fallback string
+	for _, uuid := range g.order {
+		if uuid == finished || g.status(uuid) == StatusPassed || !g.ready(uuid) {
+			continue
+		}
+		for _, prereq := range g.prereqs[uuid] {
+			if prereq == finished {
+				return g.courses[uuid], true
+			}
+		}
+		if fallback == "" {
+			fallback = uuid
+		}
+	}
+	if fallback == "" {
+		return Course{}, false
+	}
+	return g.courses[fallback], true
+}
+
+var lockedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+// render draws the graph as a tree in which each course hangs below the
+// last of its prerequisites, and names the others next to it. The course
+// highlight is marked as the suggested next one.
+func (g *courseGraph) render(highlight string) string {
+	rank := map[string]int{}
+	for i, uuid := range g.order {
+		rank[uuid] = i
+	}
+	parent := map[string]string{}
+	children := map[string][]string{}
+	var roots []string
+	for _, uuid := range g.order {
+		last := ""
+		for _, prereq := range g.prereqs[uuid] {
+			if last == "" || rank[prereq] > rank[last] {
+				last = prereq
+			}
+		}
+		// a prerequisite placed later is part of a cycle
+		if last == "" || rank[last] > rank[uuid] {
+			roots = append(roots, uuid)
+			continue
+		}
+		parent[uuid] = last
+		children[last] = append(children[last], uuid)
+	}
+
+	var b strings.Builder
+	var draw func(uuid, indent, branch string)
+	draw = func(uuid, indent, branch string) {
+		status := g.status(uuid)
+		marker := statusMarker(status)
+		if status == StatusNotStarted {
+			marker = "○"
+		}
+		title := g.courses[uuid].Title
+		if !g.ready(uuid) {
+			title = lockedStyle.Render(title)
+		}
+		line := indent + branch + marker + " " + title
+		var also []string
+		for _, prereq := range g.prereqs[uuid] {
+			if prereq != parent[uuid] {
+				also = append(also, g.courses[prereq].Title)
+			}
+		}
+		if len(also) > 0 {
+			line += lockedStyle.Render("  (also needs " + strings.Join(also, ", ") + ")")
+		}
+		if uuid == highlight {
+			line += attemptedStyle.Render("  ★ next")
+		}
+		b.WriteString(line + "\n")
+
+		switch branch {
+		case "├── ":
+			indent += "│   "
+		case "└── ":
+			indent += "    "
+		}
+		for i, child := range children[uuid] {
+			if i == len(children[uuid])-1 {
+				draw(child, indent, "└── ")
+			} else {
+				draw(child, indent, "├── ")
+			}
+		}
+	}
+	for _, root := range roots {
+		draw(root, "", "")
+	}
+	fmt.Fprintf(&b, "\n%s finished  %s started  ○ not started  %s\n",
+		statusMarker(StatusPassed), statusMarker(StatusAttempted), lockedStyle.Render("waiting for a prerequisite"))
+	return b.String()
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// maxSnapshotFileSize keeps build artifacts and other large files out of
+// attempt snapshots.
+const maxSnapshotFileSize = 1 << 20
+
+// Attempt is a snapshot of a lesson taken each time its checks are run.
+type Attempt struct {
+	Number int          `json:"Number"`
+	Time   time.Time    `json:"Time"`
+	Result LessonStatus `json:"Result"`
+	Note   string       `json:"Note,omitempty"`
+	Output string       `json:"Output"`
+	dir    string
+}
+
+// historyBrowser holds the state of the attempt history screens.
+type historyBrowser struct {
+	attempts      []Attempt
+	marked        int
+	returnState   State
+	returnContent string
+}
+
+func historyDir(uuid string) string {
+	return filepath.Join(stateDir(), "history", uuid)
+}
+
+// saveAttempt snapshots the lesson files along with the check output.
+func (m Model) saveAttempt(result LessonStatus, note string) error {
+	uuid := m.response.Lesson.UUID
+	number, err := nextAttemptNumber(uuid)
+	if err != nil {
+		return err
+	}
+	a := Attempt{
+		Number: number,
+		Time:   time.Now(),
+		Result: result,
+		Note:   note,
+		Output: m.content,
+		dir:    filepath.Join(historyDir(uuid), fmt.Sprintf("%04d", number)),
+	}
+
+	dirMode := os.FileMode(cfg.Workspace.DirMode)
+	fileMode := os.FileMode(cfg.Workspace.FileMode)
+	if err := os.MkdirAll(filepath.Join(a.dir, "files"), dirMode); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(a.dir, "attempt.json"), b, fileMode); err != nil {
+		return err
+	}
+
+	// CLI lessons share the course directory, their work lives elsewhere
+	if m.response.Lesson.Type == "type_cli" {
+		return nil
+	}
+	files, err := readLessonFiles(m.lessonDir())
+	if err != nil {
+		return err
+	}
+	for name, content := range files {
+		dst := filepath.Join(a.dir, "files", name)
+		if err := os.MkdirAll(filepath.Dir(dst), dirMode); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, []byte(content), fileMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextAttemptNumber numbers a new attempt after the highest existing one, so
+// an attempt directory that was deleted or failed to load is never reused.
+func nextAttemptNumber(uuid string) (int, error) {
+	entries, err := os.ReadDir(historyDir(uuid))
+	if errors.Is(err, os.ErrNotExist) {
+		return 1, nil
+	} else if err != nil {
+		return 0, err
+	}
+	last := 0
+	for _, entry := range entries {
+		if n, err := strconv.Atoi(entry.Name()); err == nil && n > last {
+			last = n
+		}
+	}
+	return last + 1, nil
+}
+
+// readLessonFiles returns the contents of the files in dir by relative path,
+// skipping hidden and large files.
+func readLessonFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxSnapshotFileSize {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = string(b)
+		return nil
+	})
+	return files, err
+}
+
+func loadAttempts(uuid string) ([]Attempt, error) {
+	entries, err := os.ReadDir(historyDir(uuid))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var attempts []Attempt
+	for _, entry := range entries {
+		dir := filepath.Join(historyDir(uuid), entry.Name())
+		b, err := os.ReadFile(filepath.Join(dir, "attempt.json"))
+		if err != nil {
+			continue
+		}
+		var a Attempt
+		if err := json.Unmarshal(b, &a); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", dir, err)
+		}
+		a.dir = dir
+		attempts = append(attempts, a)
+	}
+	sort.Slice(attempts, func(i, j int) bool { return attempts[i].Number < attempts[j].Number })
+	return attempts, nil
+}
+
+func (a Attempt) files() (map[string]string, error) {
+	dir := filepath.Join(a.dir, "files")
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	return readLessonFiles(dir)
+}
+
+func (a Attempt) title() string {
+	title := fmt.Sprintf("#%d  %s  %s", a.Number, a.Time.Format("2006-01-02 15:04"), a.Result)
+	if a.Note != "" {
+		title += "  (" + a.Note + ")"
+	}
+	return title
+}
+
+// render shows the attempt's output followed by its files.
+func (a Attempt) render() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n%s\n", a.title(), a.Output)
+	files, err := a.files()
+	if err != nil {
+		fmt.Fprintf(&b, "\nfailed to read files: %v\n", err)
+	}
+	for _, name := range sortedKeys(files) {
+		fmt.Fprintf(&b, "\n── %s ──\n%s\n", name, files[name])
+	}
+	return b.String()
+}
+
+// diffAttempts shows what changed in the files and output from a to b.
+func diffAttempts(a, b Attempt) (string, error) {
+	aFiles, err := a.files()
+	if err != nil {
+		return "", err
+	}
+	bFiles, err := b.files()
+	if err != nil {
+		return "", err
+	}
+	aFiles["(output)"] = a.Output
+	bFiles["(output)"] = b.Output
+
+	names := map[string]string{}
+	for name := range aFiles {
+		names[name] = ""
+	}
+	for name := range bFiles {
+		names[name] = ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s\n%s\n", incorrectStyle.Render("- "+a.title()), correctStyle.Render("+ "+b.title()))
+	for _, name := range sortedKeys(names) {
+		if aFiles[name] == bFiles[name] {
+			continue
+		}
+		fmt.Fprintf(&out, "\n── %s ──\n", name)
+		for _, line := range diff.LineDiffAsLines(aFiles[name], bFiles[name]) {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				line = correctStyle.Render(line)
+			case strings.HasPrefix(line, "-"):
+				line = incorrectStyle.Render(line)
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+	return out.String(), nil
+}
+
+// restoreAttempt puts the lesson directory back the way a left it, writing
+// its files and removing the ones it didn't have. The current files are
+// snapshotted first so the restore can be undone.
+func (m Model) restoreAttempt(a Attempt) error {
+	if m.response.Lesson.Type == "type_cli" {
+		return errors.New("CLI lessons don't snapshot their files")
+	}
+	files, err := a.files()
+	if err != nil {
+		return err
+	}
+	current, err := readLessonFiles(m.lessonDir())
+	if err != nil {
+		return err
+	}
+	m.content = ""
+	if err := m.saveAttempt(StatusAttempted, fmt.Sprintf("before restoring #%d", a.Number)); err != nil {
+		return err
+	}
+	for name := range current {
+		if _, ok := files[name]; !ok {
+			if err := os.Remove(filepath.Join(m.lessonDir(), name)); err != nil {
+				return err
+			}
+		}
+	}
+	for name, content := range files {
+		dst := filepath.Join(m.lessonDir(), name)
+		if err := os.MkdirAll(filepath.Dir(dst), os.FileMode(cfg.Workspace.DirMode)); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, []byte(content), os.FileMode(cfg.Workspace.FileMode)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m Model) historyList() list.Model {
+	items := make([]list.Item, len(m.history.attempts))
+	for i, a := range m.history.attempts {
+		status := StatusFailed
+		if a.Result == StatusPassed {
+			status = StatusPassed
+		} else if a.Note != "" {
+			status = StatusAttempted
+		}
+		marker := statusMarker(status)
+		if i == m.history.marked {
+			marker += "◆"
+		} else {
+			marker += " "
+		}
+		items[i] = item{title: a.title(), marker: marker}
+	}
+	l := m.newList(items, ItemDelegate{})
+	l.SetFilteringEnabled(false)
+	l.Title = "Attempts: " + m.response.Lesson.Title
+	return l
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	HookLessonFetched  = "lesson_fetched"
+	HookFilesWritten   = "files_written"
+	HookEditorClosed   = "editor_closed"
+	HookTestStart      = "test_start"
+	HookTestEnd        = "test_end"
+	HookPass           = "pass"
+	HookFail           = "fail"
+	HookCourseFinished = "course_finished"
+)
+
+var hookEvents = []string{
+	HookLessonFetched, HookFilesWritten, HookEditorClosed, HookTestStart,
+	HookTestEnd, HookPass, HookFail, HookCourseFinished,
+}
+
+// hookTimeout bounds how long the TUI waits for a hook.
+const hookTimeout = 30 * time.Second
+
+// hookData is written to a hook's stdin as JSON.
+type hookData struct {
+	Event        string       `json:"event"`
+	LessonUUID   string       `json:"lesson_uuid"`
+	LessonSlug   string       `json:"lesson_slug"`
+	LessonTitle  string       `json:"lesson_title"`
+	LessonType   string       `json:"lesson_type"`
+	LessonDir    string       `json:"lesson_dir"`
+	Course       string       `json:"course"`
+	CourseTitle  string       `json:"course_title"`
+	Chapter      string       `json:"chapter"`
+	ChapterTitle string       `json:"chapter_title"`
+	Status       LessonStatus `json:"status"`
+	Attempts     int          `json:"attempts"`
+	Workspace    string       `json:"workspace"`
+	Output       string       `json:"output"`
+}
+
+func (d hookData) env() []string {
+	return []string{
+		"BOOTDEV_EVENT=" + d.Event,
+		"BOOTDEV_LESSON_UUID=" + d.LessonUUID,
+		"BOOTDEV_LESSON_SLUG=" + d.LessonSlug,
+		"BOOTDEV_LESSON_TITLE=" + d.LessonTitle,
+		"BOOTDEV_LESSON_TYPE=" + d.LessonType,
+		"BOOTDEV_LESSON_DIR=" + d.LessonDir,
+		"BOOTDEV_COURSE=" + d.Course,
+		"BOOTDEV_COURSE_TITLE=" + d.CourseTitle,
+		"BOOTDEV_CHAPTER=" + d.Chapter,
+		"BOOTDEV_CHAPTER_TITLE=" + d.ChapterTitle,
+		"BOOTDEV_STATUS=" + string(d.Status),
+		"BOOTDEV_ATTEMPTS=" + strconv.Itoa(d.Attempts),
+		"BOOTDEV_WORKSPACE=" + d.Workspace,
+	}
+}
+
+// hookError is returned when a hook exits non-zero, vetoing the transition
+// it was run for.
+type hookError struct {
+	event  string
+	output string
+	err    error
+}
+
+func (e hookError) Error() string {
+	msg := fmt.Sprintf("%s hook failed: %v", e.event, e.err)
+	if e.output != "" {
+		msg += "\n" + e.output
+	}
+	return msg
+}
+
+// runHooks runs the configured hooks for events in order, stopping at the
+// first one that fails.
+func (m Model) runHooks(events ...string) error {
+	for _, event := range events {
+		command := cfg.Hooks[event]
+		if command == "" {
+			continue
+		}
+		lesson := m.response.Lesson
+		lp := progress.Lesson(lesson.UUID)
+		data := hookData{
+			Event:        event,
+			LessonUUID:   lesson.UUID,
+			LessonSlug:   lesson.Slug,
+			LessonTitle:  lesson.Title,
+			LessonType:   lesson.Type,
+			LessonDir:    m.lessonDir(),
+			Course:       lesson.CourseSlug,
+			CourseTitle:  lesson.CourseTitle,
+			Chapter:      lesson.ChapterSlug,
+			ChapterTitle: lesson.ChapterTitle,
+			Status:       lp.Status,
+			Attempts:     lp.Attempts,
+			Workspace:    workspace,
+			Output:       m.content,
+		}
+		stdin, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Dir = workspace
+		if _, err := os.Stat(data.LessonDir); err == nil && lesson.UUID != "" {
+			cmd.Dir = data.LessonDir
+		}
+		cmd.Env = append(os.Environ(), data.env()...)
+		cmd.Stdin = bytes.NewReader(stdin)
+		out, err := cmd.CombinedOutput()
+		cancel()
+		if err != nil {
+			return hookError{event: event, output: strings.TrimSpace(string(out)), err: err}
+		}
+	}
+	return nil
+}
+
+// hasHooks reports whether a hook is configured for any of events.
+func hasHooks(events ...string) bool {
+	for _, event := range events {
+		if cfg.Hooks[event] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hooksRanMsg reports that the hooks of a transition passed, so next takes
+// over and its commands run.
+type hooksRanMsg struct {
+	next Model
+	cmd  tea.Cmd
+}
+
+// runStateHooks runs the hooks for entering next.state in the background,
+// holding back next and its cmd until they pass. Meanwhile the TUI waits on
+// before, the model msg was applied to, so a veto can retry msg from there.
+func runStateHooks(before, next Model, cmd tea.Cmd, msg tea.Msg) (Model, tea.Cmd) {
+	events := stateHooks(next.state)
+	wait := before
+	wait.state = HookRunning
+	wait.runningHooks = nil
+	for _, event := range events {
+		if cfg.Hooks[event] != "" {
+			wait.runningHooks = append(wait.runningHooks, event)
+		}
+	}
+	return wait, func() tea.Msg {
+		if err := next.runHooks(events...); err != nil {
+			return vetoed(err, before.state, func() tea.Msg { return msg })
+		}
+		return hooksRanMsg{next: next, cmd: cmd}
+	}
+}
+
+// stateHooks returns the hooks that run when the TUI enters state.
+func stateHooks(state State) []string {
+	switch state {
+	case WriteFiles:
+		return []string{HookLessonFetched}
+	case EditorStart:
+		return []string{HookFilesWritten}
+	case EditorFinished:
+		return []string{HookEditorClosed}
+	case CodeTestSuccess, OutputSuccess, InputSuccess, CLIDone, QuestionCorrect:
+		return []string{HookTestEnd, HookPass}
+	case CodeTestFailed, OutputFail, InputFail, CLIFailed, QuestionFailed, QuestionRetry:
+		return []string{HookTestEnd, HookFail}
+	case CourseFinished:
+		return []string{HookCourseFinished}
+	}
+	return nil
+}
+
+// hookVetoMsg reports a hook that stopped a transition. Retrying returns to
+// resume and runs retry.
+type hookVetoMsg struct {
+	err    hookError
+	resume State
+	retry  tea.Cmd
+}
+
+// vetoed wraps the error of runHooks, which is a hookError unless the hook's
+// input could not be encoded.
+func vetoed(err error, resume State, retry tea.Cmd) tea.Msg {
+	if herr, ok := err.(hookError); ok {
+		return hookVetoMsg{err: herr, resume: resume, retry: retry}
+	}
+	return errMsg{err: err}
+}
+
+func (m *Model) showVeto(veto hookVetoMsg) {
+	m.veto = veto
+	m.state = HookFailed
+	m.title = fmt.Sprintf("The %s hook stopped the lesson", veto.err.event)
+	m.content = fmt.Sprintf("%v\n\n%s", veto.err.err, veto.err.output)
+	m.viewport = m.updateViewport()
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyMap holds every action of the TUI. The actions can be bound to other
+// keys in the [keys] section of the config file, see keyMap.bindings.
+type keyMap struct {
+	Quit      key.Binding
+	ForceQuit key.Binding
+	Help      key.Binding
+	Select    key.Binding
+	Back      key.Binding
+	Close     key.Binding
+	Edit      key.Binding
+	Attempts  key.Binding
+	Stats     key.Binding
+	Graph     key.Binding
+	Readme    key.Binding
+	Search    key.Binding
+	NextMatch key.Binding
+	PrevMatch key.Binding
+	Mark      key.Binding
+	Compare   key.Binding
+	Restore   key.Binding
+	InitRepo  key.Binding
+	FocusPane key.Binding
+	Shrink    key.Binding
+	Grow      key.Binding
+	Layout    key.Binding
+	Palette   key.Binding
+	Find      key.Binding
+	// ResultUp and ResultDown move through search results while typing.
+	ResultUp   key.Binding
+	ResultDown key.Binding
+	// PrevLesson, SkipLesson and NextUnfinished move through the course
+	// without checking or committing the current lesson.
+	PrevLesson     key.Binding
+	SkipLesson     key.Binding
+	NextUnfinished key.Binding
+	// ScrollReadme scrolls the README above quiz answers. Its keys are
+	// those of the viewport and cannot be remapped.
+	ScrollReadme key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Quit:           key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+		ForceQuit:      key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+		Help:           key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Select:         key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		Back:           key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←/h", "back")),
+		Close:          key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close")),
+		Edit:           key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "open editor")),
+		Attempts:       key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "attempts")),
+		Stats:          key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "progress")),
+		Graph:          key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "course map")),
+		Readme:         key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "README")),
+		Search:         key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+		NextMatch:      key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next match")),
+		PrevMatch:      key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "previous match")),
+		Mark:           key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark")),
+		Compare:        key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "compare with marked")),
+		Restore:        key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restore")),
+		InitRepo:       key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "git init")),
+		FocusPane:      key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "switch pane")),
+		Shrink:         key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "shrink README")),
+		Grow:           key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "grow README")),
+		Layout:         key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "toggle layout")),
+		Palette:        key.NewBinding(key.WithKeys(":", "ctrl+o"), key.WithHelp(":", "go to URL")),
+		Find:           key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "search lessons")),
+		ResultUp:       key.NewBinding(key.WithKeys("up", "ctrl+p"), key.WithHelp("↑/ctrl+p", "previous result")),
+		ResultDown:     key.NewBinding(key.WithKeys("down", "ctrl+n"), key.WithHelp("↓/ctrl+n", "next result")),
+		PrevLesson:     key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "previous lesson")),
+		SkipLesson:     key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "skip lesson")),
+		NextUnfinished: key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "next unfinished")),
+		ScrollReadme:   key.NewBinding(key.WithKeys("pgup", "pgdown", "ctrl+u", "ctrl+d"), key.WithHelp("pgup/pgdn", "scroll README")),
+	}
+}
+
+var keys = defaultKeyMap()
+
+// bindings maps the config file's action names to the bindings.
+func (k *keyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":            &k.Quit,
+		"help":            &k.Help,
+		"select":          &k.Select,
+		"back":            &k.Back,
+		"close":           &k.Close,
+		"edit":            &k.Edit,
+		"attempts":        &k.Attempts,
+		"stats":           &k.Stats,
+		"graph":           &k.Graph,
+		"readme":          &k.Readme,
+		"search":          &k.Search,
+		"next_match":      &k.NextMatch,
+		"prev_match":      &k.PrevMatch,
+		"mark":            &k.Mark,
+		"compare":         &k.Compare,
+		"restore":         &k.Restore,
+		"init_repo":       &k.InitRepo,
+		"focus_pane":      &k.FocusPane,
+		"shrink":          &k.Shrink,
+		"grow":            &k.Grow,
+		"layout":          &k.Layout,
+		"palette":         &k.Palette,
+		"find":            &k.Find,
+		"prev_lesson":     &k.PrevLesson,
+		"skip_lesson":     &k.SkipLesson,
+		"next_unfinished": &k.NextUnfinished,
+	}
+}
+
+// remap binds the actions in remaps to their new keys.
+func (k *keyMap) remap(remaps map[string][]string) error {
+	bindings := k.bindings()
+	for action, newKeys := range remaps {
+		b, ok := bindings[action]
+		if !ok {
+			names := make([]string, 0, len(bindings))
+			for name := range bindings {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown key action %q, must be one of %s", action, strings.Join(names, ", "))
+		}
+		if len(newKeys) == 0 {
+			return fmt.Errorf("no keys for %q", action)
+		}
+		b.SetKeys(newKeys...)
+		b.SetHelp(strings.Join(newKeys, "/"), b.Help().Desc)
+	}
+	return nil
+}
+
+// as returns b described as desc, for actions that differ between states.
+func as(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// inputFocused reports whether typed keys belong to a text input, in which
+// case only ctrl+c, enter and esc act on the TUI.
+func (m Model) inputFocused() bool {
+	return m.state == InputDir || m.state == LessonSearch || m.palette.open || m.readme.searching || m.list.FilterState() == list.Filtering
+}
+
+// stateKeys lists the actions available on the current screen.
+func (m Model) stateKeys() []key.Binding {
+	var bindings []key.Binding
+	switch m.state {
+	case CourseOverview:
+		bindings = append(bindings, as(keys.Select, "open"), as(keys.ScrollReadme, "scroll details"), keys.Back, keys.Graph)
+	case TrackSelect, CourseSelect, ChapterSelect, LessonSelect:
+		bindings = append(bindings, keys.Select, m.list.KeyMap.Filter, keys.Stats)
+		if m.state == TrackSelect || m.state == CourseSelect {
+			bindings = append(bindings, keys.Graph)
+		}
+		if m.state != TrackSelect {
+			bindings = append(bindings, keys.Back)
+		}
+	case QuestionStart:
+		bindings = append(bindings, as(keys.Select, "answer"))
+		if !cfg.Quiz.Exam {
+			bindings = append(bindings, keys.Readme)
+		}
+		if m.readme.quiz.Height > 0 {
+			bindings = append(bindings, keys.ScrollReadme)
+		}
+	case QuestionRetry, QuestionCorrect:
+		bindings = append(bindings, as(keys.Select, "continue"))
+	case QuestionFailed:
+		bindings = append(bindings, as(keys.Select, "continue"), keys.Readme)
+	case EditorWatch:
+		bindings = append(bindings, as(keys.Select, "check now"), as(keys.Edit, "focus editor"), keys.Readme)
+	case CodeTestSuccess, OutputSuccess, CLIDone, InputSuccess:
+		bindings = append(bindings, as(keys.Select, "commit"), keys.Attempts, keys.Readme)
+		if m.state == CodeTestSuccess {
+			bindings = append(bindings, keys.Edit)
+		}
+	case CodeTestFailed, OutputFail, CLIFailed, InputFail:
+		bindings = append(bindings, as(keys.Select, "open editor"), keys.Attempts, keys.Readme)
+	case InputDir:
+		bindings = append(bindings, as(keys.Select, "run commands"))
+	case Git:
+		bindings = append(bindings, as(keys.Select, "next lesson"))
+	case GitInit:
+		bindings = append(bindings, keys.InitRepo, as(keys.Select, "continue without committing"))
+	case HookFailed:
+		bindings = append(bindings, as(keys.Select, "retry"), keys.Edit, keys.Readme)
+	case NextLesson:
+		bindings = append(bindings, as(keys.Select, "next lesson"), keys.Stats, keys.Readme)
+	case CourseFinished:
+		if m.nextCourse != nil {
+			bindings = append(bindings, as(keys.Select, "open next course"), as(keys.Back, "select track"))
+		} else {
+			bindings = append(bindings, as(keys.Select, "select track"))
+		}
+		bindings = append(bindings, keys.Stats, keys.Graph)
+	case Dashboard, HistoryView, CourseGraph:
+		bindings = append(bindings, keys.Back)
+	case HistorySelect:
+		bindings = append(bindings, as(keys.Select, "view"), keys.Mark, keys.Compare, keys.Restore, keys.Back)
+	case LessonSearch:
+		bindings = append(bindings, as(keys.Select, "open lesson"), keys.ResultUp, keys.ResultDown, keys.Close)
+	case ReadmeView:
+		bindings = append(bindings, keys.Search, keys.NextMatch, keys.PrevMatch, as(keys.Readme, "close"))
+	}
+	if m.onLesson() {
+		bindings = append(bindings, keys.PrevLesson, keys.SkipLesson, keys.NextUnfinished)
+	}
+	if m.canOpenPalette() {
+		bindings = append(bindings, keys.Palette, keys.Find)
+	}
+	if m.splitActive() {
+		bindings = append(bindings, keys.FocusPane, keys.Shrink, keys.Grow, keys.Layout)
+	}
+	if m.inputFocused() {
+		return append(bindings, keys.ForceQuit)
+	}
+	return append(bindings, keys.Help, keys.Quit)
+}
+
+// helpLine is the one line summary of stateKeys shown below a screen.
+func (m Model) helpLine() string {
+	h := help.New()
+	h.Width = m.width
+	return "  " + h.ShortHelpView(m.stateKeys())
+}
+
+var helpStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#FF69B4")).
+	Padding(1, 2)
+
+// helpView lists every action of the current screen in a box over it.
+func (m Model) helpView() string {
+	h := help.New()
+	bindings := m.stateKeys()
+	columns := [][]key.Binding{}
+	for i := 0; i < len(bindings); i += 6 {
+		columns = append(columns, bindings[i:min(i+6, len(bindings))])
+	}
+	box := helpStyle.Render(titleStyle.UnsetMarginLeft().Render("Keys") + "\n\n" + h.FullHelpView(columns))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	paneReadme = iota
+	paneOutput
+)
+
+// minSplitWidth is the narrowest terminal the split layout is used on.
+const minSplitWidth = 100
+
+var (
+	paneStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("241"))
+
+	focusedPaneStyle = paneStyle.BorderForeground(lipgloss.Color("#FF69B4"))
+
+	statusBarStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252")).
+			Background(lipgloss.Color("237")).
+			Padding(0, 1)
+)
+
+// splitLayout shows the README next to the check output on lesson screens.
+type splitLayout struct {
+	disabled bool
+	// ratio is the share of the width taken by the README pane.
+	ratio  float64
+	focus  int
+	readme viewport.Model
+	// rendered identifies the lesson and width the README was rendered for.
+	rendered string
+}
+
+func newSplitLayout() splitLayout {
+	return splitLayout{disabled: cfg.UI.Layout == "single", ratio: 0.5, focus: paneOutput}
+}
+
+// splitActive reports whether the current screen uses the split layout.
+func (m Model) splitActive() bool {
+	if m.layout.disabled || m.width < minSplitWidth || m.response == nil || m.response.Lesson.UUID == "" {
+		return false
+	}
+	switch m.state {
+	case CodeTest, CodeTestSuccess, CodeTestFailed, CheckOutput, OutputSuccess, OutputFail,
+		CLICheck, CLIDone, CLIFailed, InputSuccess, InputFail, Git, HookFailed:
+		return true
+	}
+	return false
+}
+
+func (m Model) paneWidths() (int, int) {
+	readme := int(float64(m.width) * m.layout.ratio)
+	return readme, m.width - readme
+}
+
+// layoutPanes sizes the panes for the current screen and terminal, rendering
+// the README again when the lesson or the pane width changed.
+func (m *Model) layoutPanes() {
+	if !m.splitActive() {
+		return
+	}
+	readmeWidth, outputWidth := m.paneWidths()
+	height := m.height - lipgloss.Height(m.headerView()) - lipgloss.Height(m.statusBar()) - 2
+
+	key := fmt.Sprintf("%s/%d", m.response.Lesson.UUID, readmeWidth)
+	if m.layout.rendered != key {
+		m.layout.readme = viewport.New(readmeWidth-2, height)
+		m.layout.readme.SetContent(renderMarkdown(m.lessonReadme(), readmeWidth-2))
+		m.layout.rendered = key
+	}
+	m.layout.readme.Width = readmeWidth - 2
+	m.layout.readme.Height = height
+
+	atBottom := m.viewport.AtBottom()
+	m.viewport.Width = outputWidth - 2
+	m.viewport.Height = height
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// resizePanes moves the split between the panes by delta of the width.
+func (m *Model) resizePanes(delta float64) {
+	m.layout.ratio = min(0.8, max(0.2, m.layout.ratio+delta))
+}
+
+func (m Model) splitView(styles ...lipgloss.Style) string {
+	readmePane, outputPane := paneStyle, paneStyle
+	if m.layout.focus == paneReadme {
+		readmePane = focusedPaneStyle
+	} else {
+		outputPane = focusedPaneStyle
+	}
+	output := m.viewport
+	for _, style := range styles {
+		output.Style = style
+		break
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		readmePane.Render(m.layout.readme.View()),
+		outputPane.Render(output.View()),
+	)
+	return strings.Join([]string{m.headerView(), body, m.statusBar()}, "\n")
+}
+
+// statusBar shows where the lesson is in its course and how far along the
+// course is.
+func (m Model) statusBar() string {
+	lesson := m.response.Lesson
+	left := fmt.Sprintf("%s › %s › %s", lesson.CourseTitle, lesson.ChapterTitle, lesson.Title)
+	right := fmt.Sprintf("%s: focus  %s/%s: resize  %s: layout  %s: help",
+		keys.FocusPane.Help().Key, keys.Shrink.Help().Key, keys.Grow.Help().Key, keys.Layout.Help().Key, keys.Help.Help().Key)
+	if course := m.courseProgressResponse; course != nil && progress != nil {
+		passed, total := 0, 0
+		for _, chap := range course.Chapters {
+			for _, l := range chap.Lessons {
+				total++
+				if progress.Lesson(l.UUID).Status == StatusPassed {
+					passed++
+				}
+			}
+		}
+		right = fmt.Sprintf("%s %d/%d  ", progressBar(passed, total, 10), passed, total) + right
+	}
+	gap := max(1, m.width-lipgloss.Width(left)-lipgloss.Width(right)-2)
+	return statusBarStyle.Width(m.width).MaxWidth(m.width).Render(left + strings.Repeat(" ", gap) + right)
+}
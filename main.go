@@ -1,10 +1,8 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
-	"flag"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,11 +12,11 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
-	"strconv"
 	"strings"
-	"syscall"
+	"time"
 
 	"github.com/andreyvit/diff"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -50,6 +48,13 @@ var (
 			Bold(true).
 			Foreground(lipgloss.Color("#FF0000"))
 
+	descriptionStyle = lipgloss.NewStyle().
+				PaddingLeft(6).
+				Foreground(lipgloss.Color("241"))
+
+	attemptedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFD700"))
+
 	docStyle = lipgloss.NewStyle().Margin(1, 2)
 
 	listStyle = lipgloss.NewStyle().
@@ -75,6 +80,7 @@ const (
 	QuestionFailed
 	WriteFiles
 	EditorStart
+	EditorWatch
 	EditorFinished
 	CodeTest
 	CodeTestSuccess
@@ -90,12 +96,22 @@ const (
 	InputFail
 	InputSuccess
 	Git
+	GitInit
+	HookFailed
+	HookRunning
 	NextLesson
 	TrackSelect
 	CourseSelect
 	CourseFinished
 	ChapterSelect
 	LessonSelect
+	Dashboard
+	HistorySelect
+	HistoryView
+	ReadmeView
+	LessonSearch
+	CourseOverview
+	CourseGraph
 	Failed
 )
 
@@ -234,6 +250,7 @@ const BaseURLOverrideRequired = "override"
 type Lesson struct {
 	UUID                     string             `json:"UUID"`
 	Title                    string             `json:"Title"`
+	Type                     string             `json:"Type"`
 	LessonDataMultipleChoice MultipleChoiceData `json:"LessonDataMultipleChoice"`
 	LessonDataCodeTests      CodeData           `json:"LessonDataCodeTests"`
 	LessonDataCLI            CLIData            `json:"LessonDataCLI"`
@@ -248,6 +265,7 @@ type Check struct {
 type Response struct {
 	Lesson struct {
 		UUID             string `json:"UUID"`
+		Title            string `json:"Title"`
 		Slug             string `json:"Slug"`
 		Type             string `json:"Type"`
 		CourseUUID       string `json:"CourseUUID"`
@@ -288,88 +306,73 @@ type Response struct {
 				Answers  string `json:"Answers"`
 			} `json:"Question"`
 		} `json:"LessonDataChoice"`
-		LessonDataMultipleChoice struct {
-			Readme   string `json:"Readme"`
-			Question struct {
-				Question string   `json:"Question"`
-				Answers  []string `json:"Answers"`
-				Answer   string   `json:"Answer"`
-			} `json:"Question"`
-		} `json:"LessonDataMultipleChoice"`
-		LessonDataCLI struct {
+		LessonDataMultipleChoice MultipleChoiceData `json:"LessonDataMultipleChoice"`
+		LessonDataCLI            struct {
 			Readme  string
 			CLIData `json:"CLIData"`
 		} `json:"LessonDataCLI"`
 	} `json:"Lesson"`
-	Course struct {
-		UUID                         string   `json:"UUID"`
-		Slug                         string   `json:"Slug"`
-		Title                        string   `json:"Title"`
-		GenericTitle                 string   `json:"GenericTitle"`
-		ShortDescription             string   `json:"ShortDescription"`
-		Description                  string   `json:"Description"`
-		ThumbnailURL                 string   `json:"ThumbnailURL"`
-		PrerequisiteCourseUUIDS      []string `json:"PrerequisiteCourseUUIDS"`
-		EstimatedCompletionTimeHours int      `json:"EstimatedCompletionTimeHours"`
-		TypeDescription              string   `json:"TypeDescription"`
-		LastUpdated                  string   `json:"LastUpdated"`
-		SlugAliases                  []string `json:"SlugAliases"`
-		AuthorUUIDs                  []string `json:"AuthorUUIDs"`
-		MaintainerUUIDs              []string `json:"MaintainerUUIDs"`
-		Status                       string   `json:"Status"`
-		NumLessons                   int      `json:"NumLessons"`
-		Chapters                     []struct {
-			UUID        string `json:"UUID"`
-			Slug        string `json:"Slug"`
-			Title       string `json:"Title"`
-			Description string `json:"Description"`
-			Lessons     any    `json:"Lessons"`
-			NumLessons  int    `json:"NumLessons"`
-			CourseUUID  string `json:"CourseUUID"`
-		} `json:"Chapters"`
-		Language     string `json:"Language"`
-		CompletionXp int    `json:"CompletionXp"`
-		NumEnrolled  int    `json:"NumEnrolled"`
-		Rating       struct {
-			Average    float64 `json:"Average"`
-			TotalCount int     `json:"TotalCount"`
-		} `json:"Rating"`
-		FirstLessonUUID        string `json:"FirstLessonUUID"`
-		RecommendedCommunities []struct {
-			Link string `json:"Link"`
-			Name string `json:"Name"`
-		} `json:"RecommendedCommunities"`
-		Teachers struct {
-			Authors []struct {
-				UUID            string `json:"UUID"`
-				FirstName       string `json:"FirstName"`
-				LastName        string `json:"LastName"`
-				Slug            string `json:"Slug"`
-				Subtitle        string `json:"Subtitle"`
-				Bio             string `json:"Bio"`
-				YouTubeURL      string `json:"YouTubeURL"`
-				TwitterURL      string `json:"TwitterURL"`
-				GitHubURL       string `json:"GitHubURL"`
-				LinkedInURL     string `json:"LinkedInURL"`
-				ProfileImageURL string `json:"ProfileImageURL"`
-				TwitchURL       string `json:"TwitchURL"`
-			} `json:"Authors"`
-			Maintainers []struct {
-				UUID            string `json:"UUID"`
-				FirstName       string `json:"FirstName"`
-				LastName        string `json:"LastName"`
-				Slug            string `json:"Slug"`
-				Subtitle        string `json:"Subtitle"`
-				Bio             string `json:"Bio"`
-				YouTubeURL      string `json:"YouTubeURL"`
-				TwitterURL      string `json:"TwitterURL"`
-				GitHubURL       string `json:"GitHubURL"`
-				LinkedInURL     string `json:"LinkedInURL"`
-				ProfileImageURL string `json:"ProfileImageURL"`
-				TwitchURL       string `json:"TwitchURL"`
-			} `json:"Maintainers"`
-		} `json:"Teachers"`
-	}
+	Course CourseDetails `json:"Course"`
+}
+
+// CourseDetails is the course metadata returned by the course endpoint.
+type CourseDetails struct {
+	UUID                         string   `json:"UUID"`
+	Slug                         string   `json:"Slug"`
+	Title                        string   `json:"Title"`
+	GenericTitle                 string   `json:"GenericTitle"`
+	ShortDescription             string   `json:"ShortDescription"`
+	Description                  string   `json:"Description"`
+	ThumbnailURL                 string   `json:"ThumbnailURL"`
+	PrerequisiteCourseUUIDS      []string `json:"PrerequisiteCourseUUIDS"`
+	EstimatedCompletionTimeHours int      `json:"EstimatedCompletionTimeHours"`
+	TypeDescription              string   `json:"TypeDescription"`
+	LastUpdated                  string   `json:"LastUpdated"`
+	SlugAliases                  []string `json:"SlugAliases"`
+	AuthorUUIDs                  []string `json:"AuthorUUIDs"`
+	MaintainerUUIDs              []string `json:"MaintainerUUIDs"`
+	Status                       string   `json:"Status"`
+	NumLessons                   int      `json:"NumLessons"`
+	Chapters                     []struct {
+		UUID        string `json:"UUID"`
+		Slug        string `json:"Slug"`
+		Title       string `json:"Title"`
+		Description string `json:"Description"`
+		Lessons     any    `json:"Lessons"`
+		NumLessons  int    `json:"NumLessons"`
+		CourseUUID  string `json:"CourseUUID"`
+	} `json:"Chapters"`
+	Language     string `json:"Language"`
+	CompletionXp int    `json:"CompletionXp"`
+	NumEnrolled  int    `json:"NumEnrolled"`
+	Rating       struct {
+		Average    float64 `json:"Average"`
+		TotalCount int     `json:"TotalCount"`
+	} `json:"Rating"`
+	FirstLessonUUID        string `json:"FirstLessonUUID"`
+	RecommendedCommunities []struct {
+		Link string `json:"Link"`
+		Name string `json:"Name"`
+	} `json:"RecommendedCommunities"`
+	Teachers struct {
+		Authors     []Teacher `json:"Authors"`
+		Maintainers []Teacher `json:"Maintainers"`
+	} `json:"Teachers"`
+}
+
+type Teacher struct {
+	UUID            string `json:"UUID"`
+	FirstName       string `json:"FirstName"`
+	LastName        string `json:"LastName"`
+	Slug            string `json:"Slug"`
+	Subtitle        string `json:"Subtitle"`
+	Bio             string `json:"Bio"`
+	YouTubeURL      string `json:"YouTubeURL"`
+	TwitterURL      string `json:"TwitterURL"`
+	GitHubURL       string `json:"GitHubURL"`
+	LinkedInURL     string `json:"LinkedInURL"`
+	ProfileImageURL string `json:"ProfileImageURL"`
+	TwitchURL       string `json:"TwitchURL"`
 }
 
 type Chapter struct {
@@ -385,6 +388,7 @@ type CourseProgressResponse struct {
 
 type Course struct {
 	UUID            string `json:"UUID"`
+	Slug            string `json:"Slug"`
 	Title           string `json:"Title"`
 	FirstLessonUUID string `json:"FirstLessonUUID"`
 }
@@ -419,10 +423,12 @@ type errMsg struct {
 	err error
 }
 
+type courseMsg struct {
+	response *Response
+}
+
 type (
-	stepStartMsg struct{ cmd string }
-	stepDoneMsg  struct{ stdout string }
-	CLIErr       struct {
+	CLIErr struct {
 		cmd    string
 		dir    string
 		err    error
@@ -435,17 +441,29 @@ func (e errMsg) Error() string { return e.err.Error() }
 
 type item struct {
 	title string
+	// marker is rendered before the title, e.g. a completion status and
+	// lesson type icon.
+	marker string
+	desc   string
 }
 
 func (i item) Title() string { return i.title }
 
-func (i item) Description() string { return "" }
+func (i item) Description() string { return i.desc }
 
 func (i item) FilterValue() string { return i.title }
 
-type ItemDelegate struct{}
+type ItemDelegate struct {
+	// showDescription renders each item's description on a second line.
+	showDescription bool
+}
 
-func (d ItemDelegate) Height() int { return 1 }
+func (d ItemDelegate) Height() int {
+	if d.showDescription {
+		return 2
+	}
+	return 1
+}
 
 func (d ItemDelegate) Spacing() int { return 0 }
 
@@ -458,19 +476,26 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		return
 	}
 
-	str := i.title
-
-	fn := itemStyle.Render
+	// the marker carries its own colours, so only the title is styled
+	prefix := itemStyle.Render("")
+	style := lipgloss.NewStyle()
 	if index == m.Index() {
-		fn = func(strs ...string) string {
-			if len(strs) > 0 {
-				return selectedItemStyle.Render("> " + strs[0])
-			}
-			return ""
-		}
+		prefix = selectedItemStyle.Render("> ")
+		style = selectedItemStyle.UnsetPaddingLeft()
+	}
+	if i.marker != "" {
+		prefix += i.marker + " "
 	}
 
-	fmt.Fprint(w, fn(str))
+	fmt.Fprint(w, prefix+style.Render(i.title))
+	if d.showDescription {
+		desc := strings.ReplaceAll(i.desc, "\n", " ")
+		width := max(0, m.Width()-lipgloss.Width(descriptionStyle.Render(""))-2)
+		if lipgloss.Width(desc) > width {
+			desc = string([]rune(desc)[:max(0, width-1)]) + "…"
+		}
+		fmt.Fprint(w, "\n"+descriptionStyle.Render(desc))
+	}
 }
 
 func (m *Model) getDirSuggestions() tea.Cmd {
@@ -533,20 +558,27 @@ type Model struct {
 	codeEditor             string
 	attempts               int
 	download               bool
+	returnState            State
+	history                historyBrowser
+	panes                  editorPanes
+	veto                   hookVetoMsg
+	runningHooks           []string
+	readme                 readmeViewer
+	layout                 splitLayout
+	pendingPushes          int
+	pushRetrying           bool
+	showHelp               bool
+	palette                commandPalette
+	chapterAnchor          string
+	search                 lessonSearch
+	overview               courseOverview
+	quiz                   quizState
+	graph                  *courseGraph
+	nextCourse             *Course
 	dir                    textinput.Model
 }
 
-func convertToAPIURL(endpoint string, inputURL string) string {
-	// Extract UUID from the URL
-	parts := strings.Split(inputURL, "/")
-
-	// Return API URL regardless of UUID length
-	return fmt.Sprintf("%s%s", endpoint, parts[len(parts)-1])
-}
-
 func initialModel(url string, mdEditor string, codeEditor string) Model {
-	var lessonURL string
-	var courseURL string
 	dir := textinput.New()
 	if d, err := os.Getwd(); err == nil {
 		dir.SetValue(d)
@@ -554,37 +586,31 @@ func initialModel(url string, mdEditor string, codeEditor string) Model {
 
 	dir.ShowSuggestions = true
 	dir.Prompt = "Enter Directory: "
-	download := false
-	if !reflect.ValueOf(url).IsZero() {
-		if strings.Contains(url, "courses") {
-			courseURL = convertToAPIURL(COURSE_URL, url)
-			download = true
-		} else {
-			lessonURL = convertToAPIURL(LESSON_URL, url)
-		}
-	} else {
-		if file, err := os.ReadFile(".last"); err == nil {
-			lessonURL = string(file)
-		}
-	}
-	return Model{
-		list:                   list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
-		download:               download,
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.DisableQuitKeybindings()
+	m := Model{
+		list:                   l,
 		selectedAnswer:         0,
 		state:                  Fetch, // Start in fetch state
-		lessonURL:              lessonURL,
-		courseURL:              courseURL,
 		attempts:               0,
 		mdEditor:               mdEditor,
 		codeEditor:             codeEditor,
 		courseProgressResponse: nil,
 		response:               &Response{},
 		dir:                    dir,
+		layout:                 newSplitLayout(),
 	}
+	// the url was checked by the command, an invalid one opens the tracks
+	if t, err := parseTarget(url); err == nil {
+		m.setTarget(t.resolve())
+	} else if url == "" && progress != nil {
+		m.lessonURL = progress.Last()
+	}
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
-	return m.fetchLesson
+	return tea.Batch(m.fetchLesson, trackerTick(), syncPushes)
 }
 
 func index(l list.Model) int {
@@ -607,7 +633,86 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd  tea.Cmd
 		cmds []tea.Cmd
 	)
-	m.viewport, cmd = m.viewport.Update(msg)
+	prevState := m.state
+	// the model before msg, which waits while the hooks of a transition run
+	before := m
+
+	if msg, ok := msg.(hooksRanMsg); ok {
+		next := msg.next
+		// the window and push queue may have changed while the hooks ran
+		next.pendingPushes, next.pushRetrying = m.pendingPushes, m.pushRetrying
+		cmds = append(cmds, msg.cmd)
+		if next.width != m.width || next.height != m.height {
+			size := tea.WindowSizeMsg{Width: m.width, Height: m.height}
+			cmds = append(cmds, func() tea.Msg { return size })
+		}
+		next.enterState()
+		next.layoutPanes()
+		return next, tea.Batch(cmds...)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && m.palette.open {
+		switch {
+		case key.Matches(msg, keys.ForceQuit):
+			return m, tea.Quit
+		case key.Matches(msg, keys.Select):
+			cmd, err := m.jumpTo(m.palette.input.Value())
+			if err != nil {
+				m.palette.err = err
+				return m, nil
+			}
+			return m, cmd
+		case key.Matches(msg, keys.Close):
+			m.palette.open = false
+		default:
+			m.palette.input, cmd = m.palette.input.Update(msg)
+		}
+		return m, cmd
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && m.state == LessonSearch {
+		switch {
+		case key.Matches(msg, keys.ForceQuit):
+			return m, tea.Quit
+		case key.Matches(msg, keys.Select):
+			cmd = m.openResult()
+		case key.Matches(msg, keys.Close):
+			m.closeSearch()
+		case key.Matches(msg, keys.ResultUp, keys.ResultDown):
+			m.list, cmd = m.list.Update(msg)
+		default:
+			query := m.search.input.Value()
+			m.search.input, cmd = m.search.input.Update(msg)
+			if m.search.input.Value() != query {
+				m.runSearch()
+			}
+		}
+		return m, cmd
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && m.state == ReadmeView && m.readme.searching {
+		switch {
+		case key.Matches(msg, keys.ForceQuit):
+			return m, tea.Quit
+		case key.Matches(msg, keys.Select):
+			m.findMatches()
+		case key.Matches(msg, keys.Close):
+			m.readme.searching = false
+		default:
+			m.readme.search, cmd = m.readme.search.Update(msg)
+		}
+		return m, cmd
+	}
+
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg:
+		tracker.touch(time.Now())
+	}
+	if m.splitActive() && m.layout.focus == paneReadme {
+		m.layout.readme, cmd = m.layout.readme.Update(msg)
+	} else {
+		m.viewport, cmd = m.viewport.Update(msg)
+	}
 	cmds = append(cmds, cmd)
 	m.dir, cmd = m.dir.Update(msg)
 	cmds = append(cmds, cmd)
@@ -615,18 +720,55 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// TODO: add key to edit course/lesson url
-		//
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if m.showHelp {
+			if key.Matches(msg, keys.ForceQuit) {
+				return m, tea.Quit
+			}
+			if key.Matches(msg, keys.Help, keys.Close, keys.Quit) {
+				m.showHelp = false
+			}
+			return m, nil
+		}
+		if m.inputFocused() && !key.Matches(msg, keys.ForceQuit, keys.Select, keys.Close) {
+			break
+		}
+		switch {
+		case key.Matches(msg, keys.ForceQuit, keys.Quit):
 			return m, tea.Quit
-		case "left", "h":
+		case key.Matches(msg, keys.Help):
+			m.showHelp = true
+		case key.Matches(msg, keys.Find):
+			if m.canOpenPalette() {
+				cmds = append(cmds, m.openSearch())
+			}
+		case key.Matches(msg, keys.Palette):
+			if m.canOpenPalette() {
+				m.openPalette()
+				return m, textinput.Blink
+			}
+		case key.Matches(msg, keys.Back):
 			if m.list.FilterState() != list.Filtering {
 				switch m.state {
+				case ReadmeView:
+					m.closeReadme()
+				case Dashboard, CourseGraph:
+					m.state = m.returnState
+				case HistoryView:
+					m.state = HistorySelect
+				case HistorySelect:
+					m.state = m.history.returnState
+					m.content = m.history.returnContent
+					m.viewport = m.updateViewport()
 				case LessonSelect:
 					m.state = ChapterSelect
-					m.list = m.createList(m.courseProgressResponse.Chapters)
+					m.list = m.chapterList()
 				case ChapterSelect:
+					if c := m.courseProgressResponse; c != nil && m.overview.course.UUID == c.CourseUUID {
+						m.showOverview(m.overview)
+						break
+					}
+					fallthrough
+				case CourseOverview:
 					if m.trackURL == "" {
 						m.state = Fetch
 						cmds = append(cmds, func() tea.Msg { return request[TracksResponse](TRACKS_URL) })
@@ -634,7 +776,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.state = CourseSelect
 						m.list = m.createList(m.trackResponse.Courses)
 					}
-				case CourseSelect:
+				case CourseSelect, CourseFinished:
 					m.state = TrackSelect
 					if reflect.ValueOf(m.tracksResponse).IsZero() {
 						cmds = append(cmds, func() tea.Msg { return request[TracksResponse](TRACKS_URL) })
@@ -644,26 +786,179 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
-		case "e":
+		case key.Matches(msg, keys.Edit):
 			switch m.state {
 			case CodeTestSuccess:
 				cmds = append(cmds, m.openEditor())
+			case HookFailed:
+				if len(m.starterFiles) > 0 {
+					cmds = append(cmds, m.openEditor())
+				}
+			case EditorWatch:
+				if len(m.starterFiles) > 1 {
+					m.panes.mux.focus(m.panes.editor)
+				}
+			}
+		case key.Matches(msg, keys.PrevLesson):
+			if m.onLesson() {
+				cmds = append(cmds, m.moveLesson(-1))
+			}
+		case key.Matches(msg, keys.SkipLesson):
+			if m.onLesson() {
+				cmds = append(cmds, m.moveLesson(1))
+			}
+		case key.Matches(msg, keys.NextUnfinished):
+			if m.onLesson() {
+				cmds = append(cmds, m.nextUnfinished())
+			}
+		case key.Matches(msg, keys.Attempts):
+			switch m.state {
+			case CodeTestSuccess, CodeTestFailed, OutputSuccess, OutputFail, CLIDone, CLIFailed, InputSuccess, InputFail:
+				attempts, err := loadAttempts(m.response.Lesson.UUID)
+				if err != nil {
+					m.err = err
+					m.state = Failed
+					return m, nil
+				}
+				if len(attempts) > 0 {
+					m.history = historyBrowser{
+						attempts:      attempts,
+						marked:        -1,
+						returnState:   m.state,
+						returnContent: m.content,
+					}
+					m.state = HistorySelect
+					m.list = m.historyList()
+					m.list.Select(len(attempts) - 1)
+				}
 			}
-		case "enter":
+		case key.Matches(msg, keys.Readme):
 			if m.list.FilterState() != list.Filtering {
 				switch m.state {
-				case QuestionStart:
-					selectedAnswer := m.list.Items()[index(m.list)].(item).title
-					if selectedAnswer == m.response.Lesson.LessonDataMultipleChoice.Question.Answer {
-						m.state = QuestionCorrect // Success state
-					} else {
-						m.attempts++
-						if m.attempts >= 3 {
-							m.state = QuestionFailed // Failed state
-						} else {
-							m.state = QuestionRetry // Try again state
-						}
+				case ReadmeView:
+					m.closeReadme()
+				case QuestionStart, QuestionRetry:
+					// exams are answered without the lesson text
+					if !cfg.Quiz.Exam {
+						m.openReadme()
 					}
+				case EditorWatch, CodeTestSuccess, CodeTestFailed, OutputSuccess, OutputFail, CLIDone, CLIFailed,
+					InputSuccess, InputFail, QuestionFailed, HookFailed, NextLesson:
+					m.openReadme()
+				}
+			}
+		case key.Matches(msg, keys.FocusPane):
+			if m.splitActive() {
+				m.layout.focus = 1 - m.layout.focus
+			}
+		case key.Matches(msg, keys.Shrink):
+			if m.splitActive() {
+				m.resizePanes(-0.05)
+			}
+		case key.Matches(msg, keys.Grow):
+			if m.splitActive() {
+				m.resizePanes(0.05)
+			}
+		case key.Matches(msg, keys.Layout):
+			if m.response.Lesson.UUID != "" {
+				m.layout.disabled = !m.layout.disabled
+				m.viewport = m.updateViewport()
+			}
+		case key.Matches(msg, keys.Close):
+			if m.state == ReadmeView {
+				m.closeReadme()
+			}
+		case key.Matches(msg, keys.Search):
+			if m.state == ReadmeView {
+				m.startSearch()
+			}
+		case key.Matches(msg, keys.NextMatch):
+			if m.state == ReadmeView {
+				m.showMatch(m.readme.match + 1)
+			}
+		case key.Matches(msg, keys.PrevMatch):
+			if m.state == ReadmeView {
+				m.showMatch(m.readme.match - 1)
+			}
+		case key.Matches(msg, keys.ScrollReadme):
+			switch m.state {
+			case QuestionStart:
+				m.readme.quiz, cmd = m.readme.quiz.Update(msg)
+				cmds = append(cmds, cmd)
+			case CourseOverview:
+				m.overview.details, cmd = m.overview.details.Update(msg)
+				cmds = append(cmds, cmd)
+			}
+		case key.Matches(msg, keys.Mark):
+			if m.state == HistorySelect {
+				i := index(m.list)
+				if m.history.marked == i {
+					m.history.marked = -1
+				} else {
+					m.history.marked = i
+				}
+				m.list = m.historyList()
+				m.list.Select(i)
+			}
+		case key.Matches(msg, keys.Compare):
+			if m.state == HistorySelect && m.history.marked >= 0 && m.history.marked != index(m.list) {
+				content, err := diffAttempts(m.history.attempts[m.history.marked], m.history.attempts[index(m.list)])
+				if err != nil {
+					m.err = err
+					m.state = Failed
+					return m, nil
+				}
+				m.title = "Compare attempts"
+				m.content = content
+				m.state = HistoryView
+				m.viewport = m.updateViewport()
+			}
+		case key.Matches(msg, keys.Restore):
+			if m.state == HistorySelect {
+				a := m.history.attempts[index(m.list)]
+				if err := m.restoreAttempt(a); err != nil {
+					m.err = fmt.Errorf("failed to restore attempt #%d: %v", a.Number, err)
+					m.state = Failed
+					return m, nil
+				}
+				attempts, err := loadAttempts(m.response.Lesson.UUID)
+				if err != nil {
+					m.err = err
+					m.state = Failed
+					return m, nil
+				}
+				m.history.attempts = attempts
+				m.list = m.historyList()
+				m.list.Select(len(attempts) - 1)
+				m.title = "Restored attempt"
+				m.content = fmt.Sprintf("Restored the files of attempt #%d into %s.\nThe files it replaced were saved as attempt #%d.", a.Number, m.lessonDir(), attempts[len(attempts)-1].Number)
+				m.state = HistoryView
+				m.viewport = m.updateViewport()
+			}
+		case key.Matches(msg, keys.InitRepo):
+			if m.state == GitInit {
+				m.state = Git
+				cmds = append(cmds, m.initRepo())
+			}
+		case key.Matches(msg, keys.Graph):
+			switch m.state {
+			case TrackSelect, CourseSelect, CourseOverview, CourseFinished:
+				if m.list.FilterState() != list.Filtering {
+					cmds = append(cmds, fetchCourseGraph(true))
+				}
+			}
+		case key.Matches(msg, keys.Stats):
+			if m.list.FilterState() != list.Filtering {
+				switch m.state {
+				case TrackSelect, CourseSelect, ChapterSelect, LessonSelect, NextLesson, CourseFinished:
+					cmds = append(cmds, func() tea.Msg { return statsMsg{stats: loadStats()} })
+				}
+			}
+		case key.Matches(msg, keys.Select):
+			if m.list.FilterState() != list.Filtering {
+				switch m.state {
+				case QuestionStart:
+					m.answerQuiz()
 				case TrackSelect:
 					m.courseProgressURL = ""
 					m.lessonURL = ""
@@ -671,16 +966,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.trackURL = TRACK_URL + (*m.tracksResponse)[index(m.list)].Slug
 					cmds = append(cmds, m.fetchLesson)
 				case CourseSelect:
-					m.courseProgressURL = COURSE_PROGRESS_URL + (*m.trackResponse).Courses[index(m.list)].FirstLessonUUID
-					cmds = append(cmds, m.fetchLesson)
+					course := (*m.trackResponse).Courses[index(m.list)]
+					m.lessonURL = ""
+					m.courseURL = ""
+					m.courseProgressURL = ""
+					if course.Slug != "" {
+						m.state = Fetch
+						cmds = append(cmds, fetchOverview(course.Slug))
+					} else {
+						m.courseProgressURL = COURSE_PROGRESS_URL + course.FirstLessonUUID
+						cmds = append(cmds, m.fetchLesson)
+					}
+				case CourseOverview:
+					cmds = append(cmds, m.openOverviewItem())
 				case ChapterSelect:
 					m.chapterIndex = index(m.list)
 					m.state = LessonSelect
-					m.list = m.createList(m.courseProgressResponse.Chapters[m.chapterIndex].Lessons)
+					m.list = m.lessonList(m.chapterIndex)
+				case HistorySelect:
+					a := m.history.attempts[index(m.list)]
+					m.title = fmt.Sprintf("Attempt #%d", a.Number)
+					m.content = a.render()
+					m.state = HistoryView
+					m.viewport = m.updateViewport()
 				case LessonSelect:
 					m.lessonURL = (LESSON_URL +
 						m.courseProgressResponse.Chapters[m.chapterIndex].Lessons[index(m.list)].UUID)
 					cmds = append(cmds, m.fetchLesson)
+				case EditorWatch:
+					m.state = EditorFinished
+					cmds = append(cmds, m.getLessonType())
+				case HookFailed:
+					m.state = m.veto.resume
+					cmds = append(cmds, m.veto.retry)
 				case QuestionRetry:
 					// Reset to question state for retry
 					m.state = QuestionStart
@@ -705,8 +1023,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					cmds = append(cmds, m.openEditor())
 				case InputDir:
 					m.dir.Blur()
-					filePath := path.Join(m.lessonPath(), "dir")
-					os.WriteFile(filePath, []byte(m.response.Lesson.Slug+"\n"+m.dir.Value()), 0o755)
+					filePath := filepath.Join(m.lessonDir(), "dir")
+					os.WriteFile(filePath, []byte(m.response.Lesson.Slug+"\n"+m.dir.Value()), os.FileMode(cfg.Workspace.FileMode))
+					m.state = CLICheck
 					cmds = append(cmds, m.CLIChecks())
 				case CLIDone:
 					cmds = append(cmds, m.commitRepo())
@@ -716,9 +1035,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					cmds = append(cmds, m.commitRepo())
 				case InputFail:
 					cmds = append(cmds, m.openEditor())
-				case Git:
+				case Git, GitInit:
 					cmds = append(cmds, m.getNextLesson())
 				case CourseFinished:
+					if next := m.nextCourse; next != nil {
+						m.trackURL = ""
+						m.state = Fetch
+						if next.Slug != "" {
+							cmds = append(cmds, fetchOverview(next.Slug))
+						} else {
+							m.lessonURL, m.courseURL = "", ""
+							m.courseProgressURL = COURSE_PROGRESS_URL + next.FirstLessonUUID
+							cmds = append(cmds, m.fetchLesson)
+						}
+						break
+					}
 					m.state = TrackSelect
 					if reflect.ValueOf(m.tracksResponse).IsZero() {
 						cmds = append(cmds, func() tea.Msg { return request[TracksResponse](TRACKS_URL) })
@@ -744,6 +1075,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - verticalMarginHeight
 		}
+		m.layoutPanes()
+		switch m.state {
+		case LessonSearch:
+			m.list.SetSize(m.width, max(0, m.height-4))
+		case CourseOverview:
+			m.layoutOverview()
+		case QuestionStart:
+			m.quizReadme()
+		case QuestionFailed:
+			m.content = renderMarkdown(m.quizReview(), m.width)
+			m.viewport.SetContent(m.content)
+		case ReadmeView:
+			m.readme.rendered = renderMarkdown(m.lessonReadme(), m.width)
+			m.content = m.readme.rendered
+			m.viewport.SetContent(m.content)
+			if m.readme.query != "" {
+				m.readme.search.SetValue(m.readme.query)
+				m.findMatches()
+			}
+		}
 
 		return m, nil
 	case errMsg:
@@ -753,7 +1104,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case *CourseProgressResponse:
 		m.courseProgressResponse = msg
 		m.state = ChapterSelect
-		m.list = m.createList(m.courseProgressResponse.Chapters)
+		m.list = m.chapterList()
+		if i := m.anchoredChapter(); i >= 0 {
+			m.chapterAnchor = ""
+			m.chapterIndex = i
+			m.list.Select(i)
+			m.state = LessonSelect
+			m.list = m.lessonList(i)
+		}
 	case Model:
 		m = msg
 		switch m.state {
@@ -761,14 +1119,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, m.createCodeFiles())
 		case EditorStart:
 			cmds = append(cmds, m.openEditor())
+		case EditorWatch:
+			cmds = append(cmds, editorWatchTick())
 		case EditorFinished:
 			cmds = append(cmds, m.getLessonType())
 		case CodeTestFailed:
 		case CodeTestSuccess:
+		case CodeTest:
+			cmds = append(cmds, m.testCode())
+		case CheckOutput:
+			cmds = append(cmds, m.CheckOutput())
 		case CLICheck:
 			cmds = append(cmds, m.CLIChecks())
 		case Fetch:
 			cmds = append(cmds, m.fetchLesson)
+		case NextLesson:
+			if m.download {
+				cmds = append(cmds, m.getNextLesson())
+			}
+		case CourseFinished:
+			if m.download {
+				return m, tea.Quit
+			}
 		}
 	case *TracksResponse:
 		m.tracksResponse = msg
@@ -776,21 +1148,95 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.list = m.createList(m.tracksResponse)
 	case *TrackResponse:
 		m.trackResponse = msg
+		if m.graph != nil {
+			m.graph.sort(m.trackResponse.Courses)
+		} else {
+			cmds = append(cmds, fetchCourseGraph(false))
+		}
 		m.list = m.createList(m.trackResponse.Courses)
 		m.state = CourseSelect
+	case courseGraphMsg:
+		m.graph = msg.graph
+		if m.state == CourseSelect && m.trackResponse != nil && len(m.trackResponse.Courses) > 0 {
+			// a filter that matches nothing leaves no course selected
+			selected := ""
+			if i := index(m.list); i >= 0 && i < len(m.trackResponse.Courses) {
+				selected = m.trackResponse.Courses[i].UUID
+			}
+			m.graph.sort(m.trackResponse.Courses)
+			m.list = m.createList(m.trackResponse.Courses)
+			for i, course := range m.trackResponse.Courses {
+				if selected != "" && course.UUID == selected {
+					m.list.Select(i)
+				}
+			}
+		}
+		if msg.show {
+			m.returnState = m.state
+			m.state = CourseGraph
+			m.title = "Courses"
+			highlight := ""
+			if m.nextCourse != nil {
+				highlight = m.nextCourse.UUID
+			}
+			m.content = m.graph.render(highlight)
+			m.viewport = m.updateViewport()
+		}
 	case *Response:
 		// fmt.Println("📦 Processing response...")
 		m.response.Lesson = msg.Lesson
 		m.lessonURL = LESSON_URL + msg.Lesson.UUID
-		os.WriteFile(".last", []byte(m.lessonURL), 0o666)
+		if err := progress.SetLast(m.lessonURL); err != nil {
+			m.err = fmt.Errorf("failed to save progress: %v", err)
+			m.state = Failed
+			return m, nil
+		}
+		if !m.download {
+			if err := tracker.switchTo(msg.Lesson.UUID, m.lessonDir()); err != nil {
+				m.err = fmt.Errorf("failed to save progress: %v", err)
+				m.state = Failed
+				return m, nil
+			}
+			if m.response.Course.UUID != msg.Lesson.CourseUUID {
+				cmds = append(cmds, m.fetchCourse)
+			}
+		}
 
 		m.state = WriteFiles
 		cmds = append(cmds, m.createCodeFiles())
-	case stepStartMsg:
-		m.content += fmt.Sprintf("󰣇 ❯ %s\n", msg.cmd)
-		m.viewport = m.updateViewport()
-	case stepDoneMsg:
-		m.content += fmt.Sprintf("%s\n\n", msg.stdout)
+	case courseMsg:
+		m.response.Course = msg.response.Course
+	case hookVetoMsg:
+		m.showVeto(msg)
+	case courseOverviewMsg:
+		m.showOverview(msg.overview)
+	case searchIndexMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to index lessons: %v", msg.err)
+			m.state = Failed
+			return m, nil
+		}
+		m.search.index = msg.index
+		if m.state == LessonSearch {
+			m.runSearch()
+		}
+	case editorWatchMsg:
+		if m.state == EditorWatch {
+			if m.editorChanged() {
+				m.state = EditorFinished
+				m.panes.mux.focusTUI()
+				cmds = append(cmds, m.getLessonType())
+			} else {
+				cmds = append(cmds, editorWatchTick())
+			}
+		}
+	case trackerTickMsg:
+		cmds = append(cmds, trackerTick())
+	case statsMsg:
+		m.returnState = m.state
+		m.state = Dashboard
+		m.title = "Progress"
+		m.content = msg.stats.render(m.width)
 		m.viewport = m.updateViewport()
 	case CLIDoneMsg:
 		m.content += "done"
@@ -800,21 +1246,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.state = CLIFailed
 		m.content = fmt.Sprintf("Ran %s at %s\nFailed with error: %v\nCommand output:\n%s", msg.cmd, msg.dir, msg.err.Error(), msg.stdout)
 		m.viewport = m.updateViewport()
-	case []*exec.Cmd:
+	case Pipeline:
 		m.state = Git
-		m.content = getCmdPipe(msg[0].Stdout)
+		cmds = append(cmds, runPipeline(msg))
+	case pipelineStartMsg:
+		m.content = ""
 		m.viewport = m.updateViewport()
-		cmds = append(cmds, func() tea.Msg {
-			return m.updateCmdOutput(msg)
-		})
-	case *exec.Cmd:
-		m.state = NextLesson
-		cmds = append(cmds, m.getNextLesson())
+	case pipelineOutputMsg:
+		m.content += msg.text
+		m.viewport = m.updateViewport()
+		m.viewport.GotoBottom()
+	case pipelineStepMsg:
+		m.content += msg.result.render()
+		m.viewport = m.updateViewport()
+		m.viewport.GotoBottom()
+	case pipelineDoneMsg:
+		if msg.name == "git" {
+			m.pendingPushes = pushQueue.Len()
+			if m.pendingPushes > 0 && !m.pushRetrying {
+				m.pushRetrying = true
+				cmds = append(cmds, pushRetryTick())
+			}
+			if !pipelineFailed(msg.results) {
+				m.state = NextLesson
+				cmds = append(cmds, m.getNextLesson())
+			}
+		}
+	case pushQueueMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to save push queue: %v", msg.err)
+			m.state = Failed
+			return m, nil
+		}
+		m.pendingPushes = msg.pending
+		if msg.pending > 0 && !m.pushRetrying {
+			m.pushRetrying = true
+			cmds = append(cmds, pushRetryTick())
+		}
+	case pushRetryMsg:
+		m.pushRetrying = false
+		cmds = append(cmds, syncPushes)
 	}
 
-	m.list, cmd = m.list.Update(msg)
+	// typed keys belong to the directory prompt, not the list behind it
+	if m.state != ReadmeView && m.state != InputDir {
+		m.list, cmd = m.list.Update(msg)
+	}
 	cmds = append(cmds, cmd)
 
+	if m.state != prevState {
+		if events := stateHooks(m.state); hasHooks(events...) {
+			return runStateHooks(before, m, tea.Batch(cmds...), msg)
+		}
+		m.enterState()
+	}
+
+	m.layoutPanes()
 	return m, tea.Batch(cmds...)
 }
 
@@ -837,6 +1324,8 @@ func (m Model) updateViewport() viewport.Model {
 		m.viewport.GotoTop()
 		i := index(m.list)
 		m.viewport.ScrollDown(max(0, i-1))
+	case CourseGraph:
+		m.viewport.GotoTop()
 	default:
 		m.viewport.GotoBottom()
 	}
@@ -847,6 +1336,12 @@ func (m Model) View() string {
 	if m.err != nil {
 		return fmt.Sprintf("\n❌ Error: %v\n\nPress any key to exit...\n", m.err)
 	}
+	if m.palette.open {
+		return m.paletteView()
+	}
+	if m.showHelp {
+		return m.helpView()
+	}
 
 	// if m.download {
 	// 	if reflect.ValueOf(m.response.Lesson.UUID).IsZero() {
@@ -859,14 +1354,21 @@ func (m Model) View() string {
 	case Fetch:
 		return "\n  🔄 Fetching lesson data...\n"
 	case QuestionStart:
-		m.list.Title = m.response.Lesson.LessonDataMultipleChoice.Question.Question
+		m.list.Title = m.response.quiz().Question.Question
+		if m.readme.quiz.Height > 0 {
+			return m.readme.quiz.View() + "\n" + m.list.View()
+		}
 		return m.list.View()
 	case QuestionCorrect:
 		return "\n  ✅ Correct! Great job!\n\nPress enter to continue"
 	case QuestionRetry:
-		return fmt.Sprintf("\n  ❌ Incorrect. Try again! (%d attempts remaining)\n\n  Press enter to retry...\n", 3-m.attempts)
+		if limit := cfg.Quiz.maxAttempts(); limit > 0 {
+			return fmt.Sprintf("\n  ❌ Incorrect. Try again! (%d attempts remaining)\n\n  Press enter to retry...\n", limit-m.attempts)
+		}
+		return "\n  ❌ Incorrect. Try again!\n\n  Press enter to retry...\n"
 	case QuestionFailed:
-		return fmt.Sprintf("\n  ❌ Incorrect! The correct answer was: %s\n\n", m.response.Lesson.LessonDataMultipleChoice.Question.Answer)
+		m.title = "Review: " + m.response.Lesson.Title
+		return m.formatPager() + "\n" + m.helpLine()
 	case TrackSelect:
 		m.list.Title = "Select Track"
 		return m.list.View()
@@ -880,7 +1382,8 @@ func (m Model) View() string {
 		m.list.Title = "Select Lesson"
 		return m.list.View()
 	case CodeTest:
-		return "Testing work."
+		m.title = "Testing work"
+		return m.formatPager()
 	case CodeTestSuccess:
 		m.title = "Code Test Successful"
 		return m.formatPager(correctStyle)
@@ -888,7 +1391,8 @@ func (m Model) View() string {
 		m.title = "Code Test Failed"
 		return m.formatPager(incorrectStyle)
 	case CheckOutput:
-		return "Checking Output"
+		m.title = "Checking Output"
+		return m.formatPager()
 	case OutputSuccess:
 		m.title = "Output Matches"
 		return m.formatPager(correctStyle)
@@ -904,8 +1408,19 @@ func (m Model) View() string {
 		m.title = "Command failed"
 		return m.formatPager()
 	case Git:
-		m.title = "Pushing to repo"
-		return m.formatPager()
+		m.title = "Committing to repo"
+		if cfg.Git.Mode == GitPush {
+			m.title = "Pushing to repo"
+		}
+		return m.formatPager() + "\n" + m.helpLine()
+	case EditorWatch:
+		return fmt.Sprintf("Editing %s in %s. Saving a file runs the checks.\n\n%s", m.response.Lesson.Title, m.panes.mux.name(), m.helpLine())
+	case HookFailed:
+		return m.formatPager(incorrectStyle) + "\n" + m.helpLine()
+	case HookRunning:
+		return fmt.Sprintf("\n  ⏳ Running the %s hook...\n", strings.Join(m.runningHooks, " and "))
+	case GitInit:
+		return fmt.Sprintf("No git repository in %s.\n\n%s", workspace, m.helpLine())
 	case InputSuccess:
 		m.title = "Input Matches"
 		return m.formatPager()
@@ -913,15 +1428,35 @@ func (m Model) View() string {
 		m.title = "Input does not Match"
 		return m.formatPager()
 	case NextLesson:
-		return "Press Enter to continue to next lesson. ctrl+c: quit"
+		return "Lesson done.\n\n" + m.helpLine()
 	case CourseFinished:
-		return "Course Finished 🎊. Enter to Select Track"
+		next := "No other course is ready to start."
+		if m.nextCourse != nil {
+			next = fmt.Sprintf("Up next: %s, whose prerequisites are all done.", m.nextCourse.Title)
+		}
+		return fmt.Sprintf("Course Finished 🎊\n\n%s\n\n%s", next, m.helpLine())
+	case Dashboard, HistoryView, CourseGraph:
+		return m.formatPager()
+	case ReadmeView:
+		if m.readme.searching {
+			return m.formatPager() + "\n" + m.readme.search.View()
+		}
+		return m.formatPager() + "\n" + m.helpLine()
+	case HistorySelect:
+		return m.list.View() + "\n" + m.helpLine()
+	case CourseOverview:
+		return m.overview.details.View() + "\n" + m.list.View()
+	case LessonSearch:
+		return m.search.input.View() + "\n\n" + m.list.View() + "\n" + m.helpLine()
 	default:
 		return "\n"
 	}
 }
 
 func (m Model) formatPager(styles ...lipgloss.Style) string {
+	if m.splitActive() {
+		return m.splitView(styles...)
+	}
 	for _, style := range styles {
 		m.viewport.Style = style
 		break
@@ -931,8 +1466,33 @@ func (m Model) formatPager(styles ...lipgloss.Style) string {
 
 func (m Model) headerView() string {
 	title := titleStyle.Render(m.title)
-	line := strings.Repeat("─", max(0, m.viewport.Width-lipgloss.Width(title)))
-	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
+	timer := ""
+	if uuid := m.response.Lesson.UUID; uuid != "" && m.state != Dashboard && m.state != CourseGraph {
+		timer = "⏱ " + formatDuration(tracker.elapsed(uuid))
+		if est := m.lessonEstimate(); est > 0 {
+			timer += " / est. " + formatDuration(est)
+		}
+		timer = infoStyle.Render(timer)
+	}
+	if m.pendingPushes > 0 {
+		timer = infoStyle.Render(fmt.Sprintf("⇡%d pending  ", m.pendingPushes)) + timer
+	}
+	width := m.viewport.Width
+	if m.splitActive() {
+		width = m.width
+	}
+	line := strings.Repeat("─", max(0, width-lipgloss.Width(title)-lipgloss.Width(timer)))
+	return lipgloss.JoinHorizontal(lipgloss.Center, title, line, timer)
+}
+
+// lessonEstimate spreads the course's estimated completion time evenly over
+// its lessons.
+func (m Model) lessonEstimate() time.Duration {
+	course := m.response.Course
+	if course.NumLessons == 0 {
+		return 0
+	}
+	return time.Duration(course.EstimatedCompletionTimeHours) * time.Hour / time.Duration(course.NumLessons)
 }
 
 func (m Model) footerView() string {
@@ -953,8 +1513,7 @@ func (m Model) footerView() string {
 func request[T any](url string) tea.Msg {
 	resp, err := http.Get(url)
 	if err != nil {
-		fmt.Printf("❌ HTTP request failed: %v\n", err)
-		return errMsg{err: fmt.Errorf("failed to %s lesson: %v", url, err)}
+		return errMsg{err: fmt.Errorf("failed to %s lesson: %w", url, err)}
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -977,9 +1536,9 @@ func request[T any](url string) tea.Msg {
 
 	var response T
 	if err := json.Unmarshal(body, &response); err != nil {
-		fmt.Printf("❌ Failed to parse JSON: %v\n", err)
 		return errMsg{err: fmt.Errorf("failed to parse response: %v", err)}
 	}
+	writeCache(url, body)
 
 	// Debug: Print parsed response
 	// fmt.Printf("Parsed response:\n")
@@ -990,6 +1549,15 @@ func request[T any](url string) tea.Msg {
 	return &response
 }
 
+func (m Model) fetchCourse() tea.Msg {
+	res := cachedRequest[Response](COURSE_URL + m.response.Lesson.CourseSlug)
+	if res, ok := res.(*Response); ok {
+		return courseMsg{response: res}
+	}
+	// the course only adds the time estimate, so failing to get it is fine
+	return nil
+}
+
 func (m Model) fetchLesson() tea.Msg {
 	// fmt.Printf("🔍 Fetching lesson data from: %s\n", m.lessonURL)
 
@@ -1024,51 +1592,25 @@ func (m Model) fetchLesson() tea.Msg {
 var p *tea.Program
 
 func main() {
-	var codeEditor string
-	var mdEditor string
-	var downloadAll bool
-
-	flag.StringVar(&codeEditor, "code-editor", "", "Editor to open code files with (e.g., 'code', 'vim', 'emacs')")
-	flag.StringVar(&mdEditor, "md-editor", "", "Editor to open markdown files with (e.g., 'typora', 'code')")
-	flag.BoolVar(&downloadAll, "download", false, "Download all courses")
-	flag.Parse()
-
-	args := flag.Args()
-
-	if len(args) > 0 {
-		p = tea.NewProgram(initialModel(args[0], codeEditor, mdEditor), tea.WithAltScreen(), tea.WithMouseCellMotion())
-	} else {
-		p = tea.NewProgram(initialModel("", codeEditor, mdEditor), tea.WithAltScreen(), tea.WithMouseCellMotion())
-	}
-
-	model, err := p.Run()
-	if err != nil {
+	if err := runCLI(os.Args[1:]); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
-
-	// After program exits, check if we need to open files
-	_, ok := model.(Model)
-	if !ok {
-		fmt.Printf("Error: unexpected model type\n")
-		os.Exit(1)
-	}
 }
 
 func (m *Model) getLessonType() tea.Cmd {
 	return func() tea.Msg {
 		switch m.response.Lesson.Type {
 		case "type_choice":
-			m.state = QuestionStart
-			m.list = m.createList(m.response.Lesson.LessonDataMultipleChoice.Question.Answers)
+			m.startQuiz()
+		// the checks are started by Update once the TUI shows their state,
+		// so their output streams onto it
 		case "type_code_tests":
 			m.state = CodeTest
-			return m.testCode()()
 		case "type_code":
 			m.state = CheckOutput
-			return m.CheckOutput()()
 		case "type_cli":
-			m.dir.SetValue(m.lessonPath())
+			m.dir.SetValue(m.lessonDir())
 			m.state = InputDir
 			m.dir.Focus()
 		case "type_text_input":
@@ -1079,12 +1621,18 @@ func (m *Model) getLessonType() tea.Cmd {
 				if err.Error() != "HTTP request failed with status: 403 Forbidden" {
 					return errMsg{err: err}
 				}
+				if err := m.markPassed(); err != nil {
+					return errMsg{err: err}
+				}
 				m.state = Git
 				return m.commitRepo()()
 			} else {
 				return m.CheckInput()()
 			}
 		case "type_manual":
+			if err := m.markPassed(); err != nil {
+				return errMsg{err: err}
+			}
 			return m.commitRepo()()
 		}
 		return *m
@@ -1105,7 +1653,10 @@ func (m Model) getLessonCheck() (Check, error) {
 
 func (m *Model) CheckInput() tea.Cmd {
 	return func() tea.Msg {
-		c, err := os.ReadFile(path.Join(m.lessonPath(), "input.txt"))
+		if err := m.runHooks(HookTestStart); err != nil {
+			return vetoed(err, EditorFinished, m.getLessonType())
+		}
+		c, err := os.ReadFile(filepath.Join(m.lessonDir(), "input.txt"))
 		content := string(c)
 		if err != nil {
 			return errMsg{
@@ -1174,28 +1725,44 @@ func (m *Model) CheckInput() tea.Cmd {
 
 func (m *Model) CLIChecks() tea.Cmd {
 	return func() tea.Msg {
+		if err := m.runHooks(HookTestStart); err != nil {
+			return vetoed(err, CLICheck, m.CLIChecks())
+		}
 		cliData := m.response.Lesson.LessonDataCLI.CLIData
 		variables := make(map[string]string)
 
 		// prefer overrideBaseURL if provided, otherwise use BaseURLDefault
 
+		var failure *CLIErr
+		pl := Pipeline{Name: "cli"}
 		for _, step := range cliData.Steps {
 			if step.CLICommand != nil {
-				p.Send(stepStartMsg{cmd: step.CLICommand.Command})
-				result := m.runCLICommand(*step.CLICommand, variables)
-				for _, test := range step.CLICommand.Tests {
-					if err := m.isCLIError(result, &test, result.Variables); err != nil {
-						return CLIErr{cmd: result.FinalCommand, dir: m.dir.Value(), err: err, stdout: result.Stdout}
-					}
-				}
-				p.Send(stepDoneMsg{stdout: result.Stdout})
+				command := *step.CLICommand
+				pl.Steps = append(pl.Steps, Step{
+					Name: command.Command,
+					Run: func(w io.Writer) error {
+						result := m.runCLICommand(command, variables)
+						io.WriteString(w, result.Stdout)
+						for _, test := range command.Tests {
+							if err := m.isCLIError(result, &test, result.Variables); err != nil {
+								failure = &CLIErr{cmd: result.FinalCommand, dir: m.dir.Value(), err: err, stdout: result.Stdout}
+								return err
+							}
+						}
+						return nil
+					},
+				})
 			} else if step.HTTPRequest != nil {
 				return errMsg{errors.New("unimplemented step: HTTPRequest")}
 			} else {
 				return errMsg{errors.New("unable to run lesson: missing step")}
 			}
 		}
-		os.WriteFile(path.Join(m.lessonPath(), ".dir"), []byte(m.dir.Value()), 0o655)
+		pl.Run(sendMsg)
+		if failure != nil {
+			return *failure
+		}
+		os.WriteFile(filepath.Join(m.lessonDir(), ".dir"), []byte(m.dir.Value()), os.FileMode(cfg.Workspace.FileMode))
 		return CLIDoneMsg{}
 	}
 }
@@ -1275,7 +1842,7 @@ func (m Model) createList(titleStruct any) list.Model {
 		val = val.Elem()
 	}
 	if val.Kind() != reflect.Slice {
-		return list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0) // Return empty list if not a slice
+		return m.newList(nil, ItemDelegate{}) // Return empty list if not a slice
 	}
 
 	titles := make([]list.Item, val.Len())
@@ -1291,17 +1858,129 @@ func (m Model) createList(titleStruct any) list.Model {
 			titles[i] = item{title: v.String()}
 		}
 	}
+	return m.newList(titles, ItemDelegate{})
+}
+
+func (m Model) newList(items []list.Item, delegate ItemDelegate) list.Model {
 	// Create a simple list just to handle the items
-	l := list.New(titles, ItemDelegate{}, m.width, m.height)
+	l := list.New(items, delegate, m.width, m.height)
 	l.SetShowStatusBar(false)
 	l.SetShowTitle(true)
 	l.SetFilteringEnabled(true)
 	l.Styles.Title = titleStyle
 	l.Styles.PaginationStyle = paginationStyle
 	l.SetFilteringEnabled(true)
+	// Update quits with the remappable keys, and not while typing
+	l.DisableQuitKeybindings()
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.Select}
+	}
 	return l
 }
 
+// chapterList lists the chapters of the current course with their local
+// completion counts and descriptions.
+func (m Model) chapterList() list.Model {
+	descriptions := map[string]string{}
+	for _, chap := range m.response.Course.Chapters {
+		descriptions[chap.UUID] = chap.Description
+	}
+
+	items := make([]list.Item, len(m.courseProgressResponse.Chapters))
+	showDescription := false
+	for i, chap := range m.courseProgressResponse.Chapters {
+		passed := 0
+		for _, lesson := range chap.Lessons {
+			if progress != nil && progress.Lesson(lesson.UUID).Status == StatusPassed {
+				passed++
+			}
+		}
+		status := StatusNotStarted
+		switch {
+		case len(chap.Lessons) > 0 && passed == len(chap.Lessons):
+			status = StatusPassed
+		case passed > 0:
+			status = StatusAttempted
+		}
+		items[i] = item{
+			title:  fmt.Sprintf("%s (%d/%d)", chap.Title, passed, len(chap.Lessons)),
+			marker: statusMarker(status),
+			desc:   descriptions[chap.UUID],
+		}
+		showDescription = showDescription || descriptions[chap.UUID] != ""
+	}
+	return m.newList(items, ItemDelegate{showDescription: showDescription})
+}
+
+// lessonList lists the lessons of a chapter with their local completion
+// status and type.
+func (m Model) lessonList(chapterIndex int) list.Model {
+	lessons := m.courseProgressResponse.Chapters[chapterIndex].Lessons
+	items := make([]list.Item, len(lessons))
+	for i, lesson := range lessons {
+		status := StatusNotStarted
+		lessonType := lesson.lessonType()
+		if progress != nil {
+			lp := progress.Lesson(lesson.UUID)
+			status = lp.Status
+			if lessonType == "" {
+				lessonType = lp.Type
+			}
+		}
+		items[i] = item{
+			title:  lesson.Title,
+			marker: statusMarker(status) + " " + lessonIcon(lessonType),
+		}
+	}
+	return m.newList(items, ItemDelegate{})
+}
+
+// lessonType returns the lesson's Type, inferring it from the populated
+// lesson data when the API leaves it out.
+func (l Lesson) lessonType() string {
+	switch {
+	case l.Type != "":
+		return l.Type
+	case l.LessonDataCLI.Steps != nil:
+		return "type_cli"
+	case l.LessonDataMultipleChoice.Question.Question != "":
+		return "type_choice"
+	case l.LessonDataCodeTests.StarterFiles != nil:
+		return "type_code_tests"
+	}
+	return ""
+}
+
+func statusMarker(status LessonStatus) string {
+	switch status {
+	case StatusPassed:
+		return correctStyle.Render("✓")
+	case StatusFailed:
+		return incorrectStyle.Render("✗")
+	case StatusAttempted:
+		return attemptedStyle.Render("•")
+	}
+	return " "
+}
+
+func lessonIcon(lessonType string) string {
+	switch lessonType {
+	case "type_choice":
+		return "❓"
+	case "type_code_tests":
+		return "🧪"
+	case "type_code":
+		return "💻"
+	case "type_cli":
+		return "🐚"
+	case "type_manual":
+		return "📖"
+	case "type_text_input":
+		return "📝"
+	}
+	return "  "
+}
+
 //	func (m Model) renderList(title string) string {
 //		m.content = m.list.View()
 //		m.title = title
@@ -1311,6 +1990,8 @@ func (m Model) createList(titleStruct any) list.Model {
 //		m.viewport = m.updateViewport()
 //		return strings.Join([]string{m.headerView(), m.viewport.View(), m.footerView()}, "\n")
 //	}
+
+// lessonPath returns the lesson directory relative to the workspace root.
 func (m Model) lessonPath() string {
 	if reflect.ValueOf(m.response.Lesson.LessonDataCLI).IsZero() {
 		return path.Join(m.response.Lesson.CourseSlug, m.response.Lesson.ChapterSlug, m.response.Lesson.Slug)
@@ -1318,6 +1999,11 @@ func (m Model) lessonPath() string {
 	return m.response.Lesson.CourseSlug
 }
 
+// lessonDir returns the absolute lesson directory inside the workspace.
+func (m Model) lessonDir() string {
+	return workspacePath(m.lessonPath())
+}
+
 func (m Model) createCodeFiles() tea.Cmd {
 	return func() tea.Msg {
 		fmt.Printf(
@@ -1326,45 +2012,17 @@ func (m Model) createCodeFiles() tea.Cmd {
 		)
 
 		// Create chapter directory if it doesn't exist
-		exerciseDir := m.lessonPath()
-		if err := os.MkdirAll(exerciseDir, 0o755); err != nil {
+		exerciseDir := m.lessonDir()
+		if err := os.MkdirAll(exerciseDir, os.FileMode(cfg.Workspace.DirMode)); err != nil {
 			return errMsg{err: fmt.Errorf("failed to create exercise directory: %v", err)}
 		}
+		if err := markWorkspace(); err != nil {
+			return errMsg{err: fmt.Errorf("failed to mark workspace: %v", err)}
+		}
 
-		// Handle code lesson
-		var starterFiles []StarterFile
-		var readme string
-
-		switch m.response.Lesson.Type {
-		case "type_code_tests":
-			starterFiles = m.response.Lesson.LessonDataCodeTests.StarterFiles
-			readme = m.response.Lesson.LessonDataCodeTests.Readme
-		case "type_code":
-			if m.response.Lesson.LessonDataCodeCompletion.Readme != "" {
-				starterFiles = m.response.Lesson.LessonDataCodeCompletion.StarterFiles
-				readme = m.response.Lesson.LessonDataCodeCompletion.Readme
-			} else {
-				starterFiles = m.response.Lesson.LessonDataCodeOutput.StarterFiles
-				readme = m.response.Lesson.LessonDataCodeOutput.Readme
-			}
-		case "type_choice":
-			starterFiles = []StarterFile{}
-			readme = m.response.Lesson.LessonDataMultipleChoice.Readme
-			question := m.response.Lesson.LessonDataMultipleChoice.Question.Question
-			choices := m.response.Lesson.LessonDataMultipleChoice.Question.Answers
-			readme = fmt.Sprintf("%s\n# Question\n### %s\n- %s", readme, question, strings.Join(choices, "\n- "))
-		case "type_cli":
-			starterFiles = []StarterFile{}
-			readme = m.response.Lesson.LessonDataCLI.Readme
-		case "type_manual":
-			starterFiles = []StarterFile{}
-			readme = m.response.Lesson.LessonDataManual.Readme
-		case "type_text_input":
-			starterFiles = []StarterFile{{Name: "input.txt"}}
-			readme = m.response.Lesson.LessonDataTextInput.Readme
-
-		default:
-			return errMsg{err: fmt.Errorf("unknown lesson type: %s", m.response.Lesson.Type)}
+		starterFiles, readme, err := m.lessonFiles()
+		if err != nil {
+			return errMsg{err: err}
 		}
 
 		m.starterFiles = append(m.starterFiles, "README.md")
@@ -1374,7 +2032,7 @@ func (m Model) createCodeFiles() tea.Cmd {
 			}
 			filePath := filepath.Join(exerciseDir, file.Name)
 			if _, err := os.Stat(filePath); err != nil {
-				if err := os.WriteFile(filePath, []byte(file.Content), 0o644); err != nil {
+				if err := os.WriteFile(filePath, []byte(file.Content), os.FileMode(cfg.Workspace.FileMode)); err != nil {
 					return errMsg{err: fmt.Errorf("failed to create %s: %v", filePath, err)}
 				}
 			}
@@ -1383,7 +2041,7 @@ func (m Model) createCodeFiles() tea.Cmd {
 
 		// Create README.md in the exercise directory
 		readmePath := filepath.Join(exerciseDir, "README.md")
-		if err := os.WriteFile(readmePath, []byte(readme), 0o644); err != nil {
+		if err := os.WriteFile(readmePath, []byte(readme), os.FileMode(cfg.Workspace.FileMode)); err != nil {
 			return errMsg{err: fmt.Errorf("failed to create README.md: %v", err)}
 		}
 
@@ -1396,67 +2054,72 @@ func (m Model) createCodeFiles() tea.Cmd {
 	}
 }
 
-func (m Model) openEditor() tea.Cmd {
-	codeEditor := m.codeEditor
-	args := m.starterFiles[1:]
-	if codeEditor == "" {
-		codeEditor = "nvr"
-		args = append(args, "-cc", fmt.Sprintf("terminal glow -p %s", m.starterFiles[0]), "-cc", "vsplit", "--remote-wait-silent")
-	}
+// lessonFiles returns the starter files and README contents for the
+// current lesson type.
+func (m Model) lessonFiles() (starterFiles []StarterFile, readme string, err error) {
+	switch m.response.Lesson.Type {
+	case "type_code_tests":
+		starterFiles = m.response.Lesson.LessonDataCodeTests.StarterFiles
+		readme = m.response.Lesson.LessonDataCodeTests.Readme
+	case "type_code":
+		if m.response.Lesson.LessonDataCodeCompletion.Readme != "" {
+			starterFiles = m.response.Lesson.LessonDataCodeCompletion.StarterFiles
+			readme = m.response.Lesson.LessonDataCodeCompletion.Readme
+		} else {
+			starterFiles = m.response.Lesson.LessonDataCodeOutput.StarterFiles
+			readme = m.response.Lesson.LessonDataCodeOutput.Readme
+		}
+	case "type_choice":
+		starterFiles = []StarterFile{}
+		quiz := m.response.quiz()
+		readme = fmt.Sprintf("%s\n# Question\n### %s\n- %s", quiz.Readme, quiz.Question.Question, strings.Join(quiz.Question.Answers, "\n- "))
+	case "type_cli":
+		starterFiles = []StarterFile{}
+		readme = m.response.Lesson.LessonDataCLI.Readme
+	case "type_manual":
+		starterFiles = []StarterFile{}
+		readme = m.response.Lesson.LessonDataManual.Readme
+	case "type_text_input":
+		starterFiles = []StarterFile{{Name: "input.txt"}}
+		readme = m.response.Lesson.LessonDataTextInput.Readme
 
-	cmd := exec.Command(codeEditor, args...)
-	cmd.Dir = m.lessonPath()
-	if m.response.Lesson.Type == "type_cli" || m.response.Lesson.Type == "type_manual" || m.response.Lesson.Type == "type_text_input" {
-		cmd.Args = append(cmd.Args, "-cc", "lua vim.g.bootdev=true")
+	default:
+		return nil, "", fmt.Errorf("unknown lesson type: %s", m.response.Lesson.Type)
 	}
-
-	return tea.ExecProcess(cmd, func(err error) tea.Msg {
-		if err != nil {
-			return errMsg{
-				err: fmt.Errorf("failed to open editor: %s\nArgs: %v", err, m.starterFiles),
-			}
-		}
-		m.state = EditorFinished
-		return m
-	})
+	return starterFiles, readme, nil
 }
 
 func (m Model) testCode() tea.Cmd {
 	return func() tea.Msg {
-		var makeFile string
-		if m.response.Lesson.Type == "type_code_tests" {
-			makeFile = ".lib/" + m.response.Lesson.LessonDataCodeTests.ProgLang + "/Makefile"
-		} else {
-			if m.response.Lesson.LessonDataCodeCompletion.ProgLang != "" {
-				makeFile = ".lib/" + m.response.Lesson.LessonDataCodeCompletion.ProgLang + "/Makefile"
-			} else {
-				makeFile = ".lib/" + m.response.Lesson.LessonDataCodeOutput.ProgLang + "/Makefile"
-			}
+		if err := m.runHooks(HookTestStart); err != nil {
+			return vetoed(err, EditorFinished, m.getLessonType())
 		}
-		if _, err := os.Stat(makeFile); err != nil {
-			return errMsg{
-				err: fmt.Errorf("could not open MakeFile: %v", err),
+		var cmd *exec.Cmd
+		if runner := cfg.runner(m.progLang(), "test", m.lessonPath()); runner != "" {
+			cmd = exec.Command("sh", "-c", runner)
+		} else {
+			makeFile := workspacePath(".lib", m.progLang(), "Makefile")
+			if _, err := os.Stat(makeFile); err != nil {
+				return errMsg{
+					err: fmt.Errorf("could not open MakeFile: %v", err),
+				}
 			}
+			cmd = exec.Command("make", "-f", makeFile, m.lessonPath())
 		}
-		cmd := exec.Command("make", "-f", makeFile, m.lessonPath())
+		cmd.Dir = workspace
 
-		var stderr, stdout bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-		err := cmd.Start()
-		if err != nil {
+		// stderr is noise from the build unless the tests fail
+		res := Pipeline{Name: "test", Steps: []Step{quietCmdStep(cmd)}}.Run(sendMsg)[0]
+		if !res.started() {
 			return errMsg{
-				err: fmt.Errorf("err in starting test: %v", err),
+				err: fmt.Errorf("err in starting test: %v", res.Err),
 			}
 		}
 
-		cmd.Wait()
-
-		if cmd.ProcessState.ExitCode() != 0 {
-			m.content = stdout.String() + stderr.String()
+		m.content = res.Output
+		if res.Status == StepFailed {
 			m.state = CodeTestFailed
 		} else {
-			m.content = stdout.String()
 			m.state = CodeTestSuccess
 		}
 
@@ -1465,37 +2128,44 @@ func (m Model) testCode() tea.Cmd {
 	}
 }
 
+// progLang returns the ProgLang of whichever code lesson data is populated.
+func (m Model) progLang() string {
+	switch {
+	case m.response.Lesson.Type == "type_code_tests":
+		return m.response.Lesson.LessonDataCodeTests.ProgLang
+	case m.response.Lesson.LessonDataCodeCompletion.ProgLang != "":
+		return m.response.Lesson.LessonDataCodeCompletion.ProgLang
+	default:
+		return m.response.Lesson.LessonDataCodeOutput.ProgLang
+	}
+}
+
 func (m Model) CheckOutput() tea.Cmd {
 	return func() tea.Msg {
-		var script string
-		if m.response.Lesson.LessonDataCodeCompletion.Readme != "" {
-			script = ".lib/" + m.response.Lesson.LessonDataCodeCompletion.ProgLang + "/run"
-		} else {
-			script = ".lib/" + m.response.Lesson.LessonDataCodeOutput.ProgLang + "/run"
+		if err := m.runHooks(HookTestStart); err != nil {
+			return vetoed(err, EditorFinished, m.getLessonType())
 		}
-		if _, err := os.Stat(script); err != nil {
-			return errMsg{
-				err: fmt.Errorf("script to run program does not exist: %v", err),
+		var cmd *exec.Cmd
+		if runner := cfg.runner(m.progLang(), "run", m.lessonPath()); runner != "" {
+			cmd = exec.Command("sh", "-c", runner)
+		} else {
+			script := workspacePath(".lib", m.progLang(), "run")
+			if _, err := os.Stat(script); err != nil {
+				return errMsg{
+					err: fmt.Errorf("script to run program does not exist: %v", err),
+				}
 			}
+			cmd = exec.Command("bash", script, m.lessonPath())
 		}
-		cmd := exec.Command("bash", script, m.lessonPath())
+		cmd.Dir = workspace
 
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		cmd.Stderr = &out
-		err := cmd.Start()
-		if err != nil {
+		res := Pipeline{Name: "run", Steps: []Step{cmdStep(cmd)}}.Run(sendMsg)[0]
+		if !res.started() {
 			return errMsg{
-				err: fmt.Errorf("err in starting test: %v", err),
+				err: fmt.Errorf("err in starting test: %v", res.Err),
 			}
 		}
-
-		cmd.Wait()
-
-		if cmd.ProcessState.ExitCode() != 0 {
-			m.content = out.String()
-			m.state = OutputFail
-		}
+		out := res.Output
 
 		var expectedOutput string
 		if m.response.Lesson.LessonDataCodeCompletion.CodeExpectedOutput != "" {
@@ -1504,11 +2174,11 @@ func (m Model) CheckOutput() tea.Cmd {
 			expectedOutput = m.response.Lesson.LessonDataCodeOutput.CodeExpectedOutput
 		}
 
-		if out.String() == expectedOutput {
-			m.content = out.String()
+		if out == expectedOutput {
+			m.content = out
 			m.state = OutputSuccess
 		} else {
-			m.content = diff.CharacterDiff(out.String(), expectedOutput)
+			m.content = diff.CharacterDiff(out, expectedOutput)
 			m.state = OutputFail
 		}
 		m.viewport = m.updateViewport()
@@ -1516,136 +2186,8 @@ func (m Model) CheckOutput() tea.Cmd {
 	}
 }
 
-func (m Model) commitRepo() tea.Cmd {
-	return func() tea.Msg {
-		var stdout bytes.Buffer
-		if _, err := os.Stat(".git"); err != nil {
-			return errMsg{
-				err: fmt.Errorf("initialize git repo"),
-			}
-		}
-
-		path := m.lessonPath()
-		var cmds []*exec.Cmd
-		cmds = append(cmds, exec.Command("git", "add", path))
-		cmds[0].Stdout = &stdout
-		cmds[0].Stderr = &bytes.Buffer{}
-
-		courseSlug := m.response.Lesson.CourseSlug
-		lessonNum := m.getLessonNumber()
-		chapNum := m.getChapterNumber()
-		msg := fmt.Sprintf("%s - Chapter %d - Lesson %d", courseSlug, chapNum, lessonNum)
-		cmds = append(cmds, exec.Command("git", "commit", "-m", msg))
-		args := []string{"push"}
-		if pid, err := GetTracerPid(); err == nil && pid > 0 {
-			args = append(args, "-n", "-v")
-		}
-		cmds = append(cmds, exec.Command("git", args...))
-
-		m.state = Git
-		return cmds
-	}
-}
-
-func (m Model) updateCmdOutput(cmds []*exec.Cmd) tea.Msg {
-	cmd := cmds[0]
-	if cmd.Process == nil {
-		fmt.Fprintf(cmd.Stdout, "󰣇 ❯ %v", strings.Join(cmd.Args, " "))
-		if err := cmd.Start(); err != nil {
-			return errMsg{
-				err: fmt.Errorf("failed to start %v\n%v", strings.Join(cmd.Args, " "), err),
-			}
-		}
-		cmd.Stderr = &bytes.Buffer{}
-	} else {
-		var status syscall.WaitStatus
-		pid, err := syscall.Wait4(cmd.Process.Pid, &status, syscall.WNOHANG, nil)
-		if err != nil {
-			return errMsg{
-				err: fmt.Errorf("failed to reap process %d: %v", cmd.Process.Pid, err),
-			}
-		}
-		if pid > 0 {
-			if status.Exited() {
-				if status.ExitStatus() != 0 {
-					fmt.Fprintf(cmd.Stdout, "\"%v\" failed with exit code %v\n%v", strings.Join(cmd.Args, " "), status.ExitStatus(), getCmdPipe(cmd.Stderr))
-				}
-				if len(cmds) == 1 {
-					return cmd
-				} else {
-					cmds[1].Stdout = cmd.Stdout
-					cmds = cmds[1:]
-				}
-			}
-		}
-	}
-	return cmds
-}
-
-func getCmdPipe(w io.Writer) string {
-	e := ""
-	switch a := w.(type) {
-	case *bytes.Buffer:
-		e = a.String()
-	}
-	return e
-}
-
 func (m Model) getNextLesson() tea.Cmd {
-	return func() tea.Msg {
-		m.attempts = 0
-		if m.courseProgressResponse == nil {
-			res := request[CourseProgressResponse](COURSE_PROGRESS_URL + m.response.Lesson.UUID)
-			switch prog := res.(type) {
-			case *CourseProgressResponse:
-				m.courseProgressResponse = prog
-			case errMsg:
-				return errMsg{err: prog.err}
-			}
-		}
-
-		if !m.download {
-			m.commitRepo()
-		}
-		m.starterFiles = []string{}
-		currChapNum := m.getChapterNumber() - 1
-		currChap := m.courseProgressResponse.Chapters[currChapNum]
-		chap := currChap
-		nextLessonNum := m.getLessonNumber()
-		if nextLessonNum >= len(currChap.Lessons) {
-			if currChapNum+1 >= len(m.courseProgressResponse.Chapters) {
-				m.state = CourseFinished
-				return m
-			}
-			chap = m.courseProgressResponse.Chapters[currChapNum+1]
-			nextLessonNum = 0
-		}
-		m.lessonURL = LESSON_URL + chap.Lessons[nextLessonNum].UUID
-		m.state = Fetch
-		return m
-	}
-}
-
-// Extract chapter number from ChapterSlug (format: "7-advanced-pointers" -> 7)
-func (m Model) getChapterNumber() int {
-	parts := strings.Split(m.response.Lesson.ChapterSlug, "-")
-	if len(parts) > 0 {
-		if num, err := strconv.Atoi(parts[0]); err == nil {
-			return num
-		}
-	}
-	return 0
-}
-
-// Extract lesson number from Slug (format: "2-pointer-array" -> 2)
-func (m Model) getLessonNumber() int {
-	parts := strings.Split(m.response.Lesson.Slug, "-")
-	if len(parts) > 0 {
-		if num, err := strconv.Atoi(parts[0]); err == nil {
-			return num
-		}
-	}
-	return 0
+	return m.moveLesson(1)
 }
 
 func GetTracerPid() (int, error) {
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quits reports whether cmd, or any command it batches, quits the program.
+// Commands still running after a moment, like cursor blinks, are ignored.
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		switch msg := msg.(type) {
+		case tea.QuitMsg:
+			return true
+		case tea.BatchMsg:
+			for _, cmd := range msg {
+				if quits(cmd) {
+					return true
+				}
+			}
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+	return false
+}
+
+func TestInputDirTypesQuitKey(t *testing.T) {
+	m := initialModel("", "", "")
+	m.state = InputDir
+	m.dir.SetValue("/tmp/")
+	m.dir.Focus()
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if quits(cmd) {
+		t.Fatal("typing q into the directory prompt quit the program")
+	}
+	m = next.(Model)
+	if m.state != InputDir {
+		t.Errorf("state = %v, want InputDir", m.state)
+	}
+	if got := m.dir.Value(); !strings.HasSuffix(got, "q") {
+		t.Errorf("directory = %q, want the typed q", got)
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); !quits(cmd) {
+		t.Error("ctrl+c did not quit from the directory prompt")
+	}
+}
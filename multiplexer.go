@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// multiplexer opens the editor and README in panes next to the TUI, so the
+// TUI keeps running and can check the lesson whenever a file is saved.
+type multiplexer interface {
+	name() string
+	// open runs name with args in a new pane and returns the pane's id, or
+	// "" if the multiplexer does not report one. focus keeps the focus on
+	// the new pane.
+	open(dir, direction string, focus bool, name string, args ...string) (string, error)
+	focus(pane string) error
+	focusTUI() error
+	alive(pane string) bool
+	close(pane string) error
+}
+
+// detectMultiplexer returns the multiplexer selected by editor.multiplexer,
+// or nil if panes should not be used.
+func detectMultiplexer() multiplexer {
+	mode := cfg.Editor.Multiplexer
+	if mode == "auto" {
+		switch {
+		case os.Getenv("TMUX") != "":
+			mode = "tmux"
+		case os.Getenv("ZELLIJ") != "":
+			mode = "zellij"
+		}
+	}
+	switch mode {
+	case "tmux":
+		return tmux{self: os.Getenv("TMUX_PANE")}
+	case "zellij":
+		return zellij{}
+	}
+	return nil
+}
+
+type tmux struct {
+	self string
+}
+
+func (tmux) name() string { return "tmux" }
+
+func (t tmux) open(dir, direction string, focus bool, name string, args ...string) (string, error) {
+	split := []string{"split-window", "-c", dir, "-P", "-F", "#{pane_id}"}
+	if direction == "right" {
+		split = append(split, "-h")
+	} else {
+		split = append(split, "-v")
+		if t.self != "" {
+			split = append(split, "-t", t.self)
+		}
+	}
+	if !focus {
+		split = append(split, "-d")
+	}
+	split = append(split, "--", name)
+	out, err := exec.Command("tmux", append(split, args...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux split-window failed: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (tmux) focus(pane string) error {
+	if err := exec.Command("tmux", "select-window", "-t", pane).Run(); err != nil {
+		return err
+	}
+	return exec.Command("tmux", "select-pane", "-t", pane).Run()
+}
+
+func (t tmux) focusTUI() error {
+	if t.self == "" {
+		return nil
+	}
+	return t.focus(t.self)
+}
+
+func (tmux) alive(pane string) bool {
+	return exec.Command("tmux", "display-message", "-p", "-t", pane, "#{pane_id}").Run() == nil
+}
+
+func (tmux) close(pane string) error {
+	return exec.Command("tmux", "kill-pane", "-t", pane).Run()
+}
+
+// zellij has no pane ids on the command line, so its panes are open-only:
+// they close when their program exits, and the TUI neither reuses nor
+// closes them. Panes are focused by moving in the direction they were
+// opened.
+type zellij struct{}
+
+func (zellij) name() string { return "zellij" }
+
+func (zellij) open(dir, direction string, focus bool, name string, args ...string) (string, error) {
+	run := []string{"run", "--direction", direction, "--cwd", dir, "--close-on-exit", "--", name}
+	if err := exec.Command("zellij", append(run, args...)...).Run(); err != nil {
+		return "", fmt.Errorf("zellij run failed: %v", err)
+	}
+	if !focus {
+		opposite := map[string]string{"right": "left", "down": "up"}[direction]
+		exec.Command("zellij", "action", "move-focus", opposite).Run()
+	}
+	return "", nil
+}
+
+// focus moves the focus right, where the editor opens.
+func (zellij) focus(string) error {
+	return exec.Command("zellij", "action", "move-focus", "right").Run()
+}
+
+func (zellij) focusTUI() error {
+	return exec.Command("zellij", "action", "move-focus", "left").Run()
+}
+
+func (zellij) alive(string) bool { return false }
+
+func (zellij) close(string) error { return nil }
+
+// editorPanes are the panes opened for the current lesson.
+type editorPanes struct {
+	mux     multiplexer
+	lesson  string
+	editor  string
+	readme  string
+	watched time.Time
+}
+
+// readmePager renders the README in a pane: glow if installed, else less.
+func readmePager(readme string) (string, []string) {
+	if _, err := exec.LookPath("glow"); err == nil {
+		return "glow", []string{"-p", readme}
+	}
+	return "less", []string{readme}
+}
+
+// openPanes opens the lesson's README below the TUI and the code editor to
+// its right, closing the panes of a previous lesson. If the panes of this
+// lesson are still open, the editor is focused instead.
+func (m Model) openPanes(mux multiplexer) tea.Cmd {
+	return func() tea.Msg {
+		uuid := m.response.Lesson.UUID
+		panes := m.panes
+		if panes.mux != nil && panes.lesson == uuid && panes.editor != "" && panes.mux.alive(panes.editor) {
+			if err := panes.mux.focus(panes.editor); err != nil {
+				return errMsg{err: fmt.Errorf("failed to focus editor: %v", err)}
+			}
+		} else {
+			if panes.mux != nil {
+				for _, pane := range []string{panes.editor, panes.readme} {
+					if pane != "" && panes.mux.alive(pane) {
+						panes.mux.close(pane)
+					}
+				}
+			}
+			panes = editorPanes{mux: mux, lesson: uuid}
+			dir := m.lessonDir()
+			readme := m.starterFiles[0]
+			files := m.starterFiles[1:]
+			var err error
+
+			if m.mdEditor != "" {
+				if err := m.openMarkdownEditor(); err != nil {
+					return errMsg{err: err}
+				}
+			} else {
+				name, args := readmePager(readme)
+				if panes.readme, err = mux.open(dir, "down", len(files) == 0, name, args...); err != nil {
+					return errMsg{err: err}
+				}
+			}
+			if len(files) > 0 {
+				name, args := editorCommand(m.editorFor())
+				if panes.editor, err = mux.open(dir, "right", true, name, m.editorArgs(name, args, "")...); err != nil {
+					return errMsg{err: err}
+				}
+			}
+		}
+		panes.watched = latestModTime(m.lessonDir())
+		m.panes = panes
+		m.state = EditorWatch
+		return m
+	}
+}
+
+type editorWatchMsg struct{}
+
+func editorWatchTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return editorWatchMsg{} })
+}
+
+// editorChanged reports whether a lesson file was saved or the editor pane
+// closed since the panes were opened.
+func (m *Model) editorChanged() bool {
+	if mod := latestModTime(m.lessonDir()); mod.After(m.panes.watched) {
+		m.panes.watched = mod
+		return true
+	}
+	return m.panes.editor != "" && !m.panes.mux.alive(m.panes.editor)
+}
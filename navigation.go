@@ -0,0 +1,137 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// position returns the chapter and lesson index of the lesson uuid, or -1
+// and -1 if the course does not contain it.
+func (c *CourseProgressResponse) position(uuid string) (int, int) {
+	for i, chap := range c.Chapters {
+		for j, l := range chap.Lessons {
+			if l.UUID == uuid {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// lessonUUIDs lists the course's lessons in order.
+func (c *CourseProgressResponse) lessonUUIDs() []string {
+	var uuids []string
+	for _, chap := range c.Chapters {
+		for _, l := range chap.Lessons {
+			uuids = append(uuids, l.UUID)
+		}
+	}
+	return uuids
+}
+
+// courseProgress returns the structure of the current lesson's course,
+// fetching it if it is not known yet or belongs to another course.
+func (m Model) courseProgress() (*CourseProgressResponse, error) {
+	if c := m.courseProgressResponse; c != nil {
+		if chap, _ := c.position(m.response.Lesson.UUID); chap >= 0 {
+			return c, nil
+		}
+	}
+	switch res := request[CourseProgressResponse](COURSE_PROGRESS_URL + m.response.Lesson.UUID).(type) {
+	case *CourseProgressResponse:
+		return res, nil
+	case errMsg:
+		return nil, res.err
+	}
+	return nil, nil
+}
+
+// lessonPosition returns the 1-based chapter and lesson numbers of the
+// current lesson, fetching the course structure if needed. ok is false when
+// the course does not list the lesson.
+func (m Model) lessonPosition() (chapter, lesson int, ok bool) {
+	c, err := m.courseProgress()
+	if err != nil || c == nil {
+		return 0, 0, false
+	}
+	chap, l := c.position(m.response.Lesson.UUID)
+	if chap < 0 {
+		return 0, 0, false
+	}
+	return chap + 1, l + 1, true
+}
+
+// moveLesson goes delta lessons forward or back in the course. Moving past
+// the last lesson finishes the course, moving before the first one stays.
+func (m Model) moveLesson(delta int) tea.Cmd {
+	return m.gotoLesson(func(uuids []string, current int) int {
+		return max(0, current+delta)
+	})
+}
+
+// nextUnfinished goes to the first lesson after the current one that has
+// not been passed, wrapping around to the start of the course. The course is
+// finished when every lesson has been passed.
+func (m Model) nextUnfinished() tea.Cmd {
+	return m.gotoLesson(func(uuids []string, current int) int {
+		for i := 1; i <= len(uuids); i++ {
+			next := (current + i) % len(uuids)
+			if progress == nil || progress.Lesson(uuids[next]).Status != StatusPassed {
+				return next
+			}
+		}
+		return len(uuids)
+	})
+}
+
+// gotoLesson fetches the lesson at the index pick returns, given the course's
+// lessons and the index of the current one.
+func (m Model) gotoLesson(pick func(uuids []string, current int) int) tea.Cmd {
+	return func() tea.Msg {
+		course, err := m.courseProgress()
+		if err != nil {
+			return errMsg{err: err}
+		}
+		m.courseProgressResponse = course
+		m.attempts = 0
+		m.starterFiles = []string{}
+
+		uuids := course.lessonUUIDs()
+		current := -1
+		for i, uuid := range uuids {
+			if uuid == m.response.Lesson.UUID {
+				current = i
+			}
+		}
+		next := pick(uuids, current)
+		if next >= len(uuids) {
+			m.state = CourseFinished
+			m.nextCourse = nil
+			if !m.download {
+				if m.graph == nil {
+					m.graph, _ = loadCourseGraph()
+				}
+				if m.graph != nil {
+					if c, ok := m.graph.suggest(course.CourseUUID); ok {
+						m.nextCourse = &c
+					}
+				}
+			}
+			return m
+		}
+		m.lessonURL = LESSON_URL + uuids[next]
+		m.state = Fetch
+		return m
+	}
+}
+
+// onLesson reports whether the current screen shows a lesson that can be
+// left for another one.
+func (m Model) onLesson() bool {
+	switch m.state {
+	case EditorWatch, CodeTestSuccess, CodeTestFailed, OutputSuccess, OutputFail, CLIDone, CLIFailed,
+		InputSuccess, InputFail, QuestionStart, QuestionRetry, QuestionCorrect, QuestionFailed,
+		HookFailed, GitInit, NextLesson:
+		return m.response.Lesson.UUID != ""
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// commandPalette jumps to a lesson, course or track from anywhere in the TUI.
+type commandPalette struct {
+	open  bool
+	input textinput.Model
+	err   error
+}
+
+// canOpenPalette reports whether the current screen is idle, so jumping
+// away does not race a running check or commit.
+func (m Model) canOpenPalette() bool {
+	switch m.state {
+	case TrackSelect, CourseSelect, CourseOverview, ChapterSelect, LessonSelect, Dashboard, HistorySelect, HistoryView,
+		ReadmeView, CourseFinished:
+		return true
+	}
+	return m.onLesson()
+}
+
+func (m *Model) openPalette() {
+	m.palette.input = textinput.New()
+	m.palette.input.Placeholder = "lesson UUID or URL, course or track URL or slug"
+	m.palette.input.Prompt = ": "
+	m.palette.input.Width = max(20, m.width/2)
+	m.palette.input.Focus()
+	m.palette.err = nil
+	m.palette.open = true
+}
+
+// jumpTo points the model at the lesson, course or track the palette input
+// names and fetches it. Courses and tracks open on their selection screens.
+func (m *Model) jumpTo(input string) (tea.Cmd, error) {
+	t, err := parseTarget(input)
+	if err != nil {
+		return nil, err
+	}
+	m.palette.open = false
+	jump := *m
+	return func() tea.Msg {
+		jump.setTarget(t.resolve())
+		jump.state = Fetch
+		return jump
+	}, nil
+}
+
+var paletteErrStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+// paletteView shows the palette in a box over the screen.
+func (m Model) paletteView() string {
+	body := titleStyle.UnsetMarginLeft().Render("Go to") + "\n\n" + m.palette.input.View()
+	if m.palette.err != nil {
+		body += "\n\n" + paletteErrStyle.Render(m.palette.err.Error())
+	}
+	box := helpStyle.Render(body)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
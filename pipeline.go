@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type StepStatus int
+
+const (
+	StepPending StepStatus = iota
+	StepRunning
+	StepPassed
+	StepFailed
+	StepSkipped
+)
+
+// Step is one stage of a Pipeline. Run writes its output to w and returns
+// an error if the step failed.
+type Step struct {
+	Name string
+	Run  func(w io.Writer) error
+	// ContinueOnError runs the following steps even if this one fails.
+	ContinueOnError bool
+}
+
+type StepResult struct {
+	Name     string
+	Status   StepStatus
+	ExitCode int
+	Output   string
+	Err      error
+	Duration time.Duration
+	// Optional is set for steps that continue on error.
+	Optional bool
+}
+
+// Pipeline runs its steps one after another, streaming their output. Once a
+// step fails, the remaining steps are skipped unless it continues on error.
+type Pipeline struct {
+	Name  string
+	Steps []Step
+}
+
+type (
+	pipelineStartMsg  struct{ name string }
+	pipelineOutputMsg struct{ text string }
+	pipelineStepMsg   struct {
+		index  int
+		result StepResult
+	}
+	pipelineDoneMsg struct {
+		name    string
+		results []StepResult
+	}
+)
+
+// cmdStep runs cmd as a step, failing on a non-zero exit code.
+func cmdStep(cmd *exec.Cmd) Step {
+	return Step{
+		Name: strings.Join(cmd.Args, " "),
+		Run: func(w io.Writer) error {
+			cmd.Stdout = w
+			cmd.Stderr = w
+			return cmd.Run()
+		},
+	}
+}
+
+// quietCmdStep is cmdStep, but holds back cmd's stderr and only writes it
+// after the output if the step fails.
+func quietCmdStep(cmd *exec.Cmd) Step {
+	return Step{
+		Name: strings.Join(cmd.Args, " "),
+		Run: func(w io.Writer) error {
+			var stderr bytes.Buffer
+			cmd.Stdout = w
+			cmd.Stderr = &stderr
+			err := cmd.Run()
+			if err != nil {
+				w.Write(stderr.Bytes())
+			}
+			return err
+		},
+	}
+}
+
+// streamWriter forwards everything written to it to send while keeping a
+// copy of the output.
+type streamWriter struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	send func(tea.Msg)
+}
+
+func (w *streamWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.send(pipelineOutputMsg{text: string(b)})
+	return w.buf.Write(b)
+}
+
+// Run executes the pipeline, reporting progress through send, and returns
+// the result of every step.
+func (pl Pipeline) Run(send func(tea.Msg)) []StepResult {
+	if send == nil {
+		send = func(tea.Msg) {}
+	}
+	send(pipelineStartMsg{name: pl.Name})
+
+	results := make([]StepResult, len(pl.Steps))
+	failed := false
+	for i, step := range pl.Steps {
+		res := StepResult{Name: step.Name, Status: StepRunning, Optional: step.ContinueOnError}
+		if failed {
+			res.Status = StepSkipped
+			results[i] = res
+			send(pipelineStepMsg{index: i, result: res})
+			continue
+		}
+		send(pipelineStepMsg{index: i, result: res})
+
+		w := &streamWriter{send: send}
+		start := time.Now()
+		err := step.Run(w)
+		res.Duration = time.Since(start)
+		res.Output = w.buf.String()
+		res.Status = StepPassed
+		if err != nil {
+			res.Status = StepFailed
+			res.Err = err
+			res.ExitCode = -1
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				res.ExitCode = exitErr.ExitCode()
+			}
+			failed = !step.ContinueOnError
+		}
+		results[i] = res
+		send(pipelineStepMsg{index: i, result: res})
+	}
+	return results
+}
+
+// runPipeline runs pl in the background of the TUI, streaming to the program.
+func runPipeline(pl Pipeline) tea.Cmd {
+	return func() tea.Msg {
+		return pipelineDoneMsg{name: pl.Name, results: pl.Run(sendMsg)}
+	}
+}
+
+// sendMsg forwards msg to the TUI, if there is one.
+func sendMsg(msg tea.Msg) {
+	if p != nil {
+		p.Send(msg)
+	}
+}
+
+// started reports whether the step got to run, as opposed to failing to
+// start its command.
+func (res StepResult) started() bool {
+	var exitErr *exec.ExitError
+	return res.Err == nil || errors.As(res.Err, &exitErr)
+}
+
+// pipelineFailed reports whether a step failed that was not optional.
+func pipelineFailed(results []StepResult) bool {
+	for _, res := range results {
+		if res.Status == StepFailed && !res.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// render formats the outcome of a step below its output.
+func (res StepResult) render() string {
+	switch res.Status {
+	case StepRunning:
+		return fmt.Sprintf("󰣇 ❯ %s\n", res.Name)
+	case StepPassed:
+		return correctStyle.Render(fmt.Sprintf("✓ done in %s", res.Duration.Round(time.Millisecond))) + "\n\n"
+	case StepFailed:
+		if res.ExitCode >= 0 {
+			return incorrectStyle.Render(fmt.Sprintf("✗ failed with exit code %d", res.ExitCode)) + "\n\n"
+		}
+		return incorrectStyle.Render(fmt.Sprintf("✗ %v", res.Err)) + "\n\n"
+	case StepSkipped:
+		return fmt.Sprintf("- skipped %s\n", res.Name)
+	}
+	return ""
+}
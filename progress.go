@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type LessonStatus string
+
+const (
+	StatusNotStarted LessonStatus = "not_started"
+	StatusAttempted  LessonStatus = "attempted"
+	StatusPassed     LessonStatus = "passed"
+	StatusFailed     LessonStatus = "failed"
+)
+
+// LessonProgress is the locally recorded state of a single lesson.
+type LessonProgress struct {
+	UUID         string       `json:"UUID"`
+	Slug         string       `json:"Slug"`
+	Title        string       `json:"Title"`
+	Type         string       `json:"Type"`
+	CourseUUID   string       `json:"CourseUUID"`
+	CourseSlug   string       `json:"CourseSlug"`
+	CourseTitle  string       `json:"CourseTitle"`
+	ChapterUUID  string       `json:"ChapterUUID"`
+	ChapterSlug  string       `json:"ChapterSlug"`
+	ChapterTitle string       `json:"ChapterTitle"`
+	Status       LessonStatus `json:"Status"`
+	Attempts     int          `json:"Attempts"`
+	StartedAt    time.Time    `json:"StartedAt"`
+	LastAttempt  time.Time    `json:"LastAttempt"`
+	CompletedAt  time.Time    `json:"CompletedAt"`
+	// ActiveTime excludes idle periods, see activityTracker.
+	ActiveTime time.Duration `json:"ActiveTime"`
+	LastOutput string        `json:"LastOutput"`
+	// WrongAnswers counts how often each wrong quiz answer was picked.
+	WrongAnswers map[string]int `json:"WrongAnswers,omitempty"`
+}
+
+// ProgressStore records the status, attempts and time spent on every lesson,
+// and the last lesson opened, in .bootdev-local/progress.json. mu guards the
+// lessons and the file, since checks record progress from their tea.Cmds.
+type ProgressStore struct {
+	mu         sync.Mutex
+	path       string
+	LastLesson string                     `json:"LastLesson"`
+	Lessons    map[string]*LessonProgress `json:"Lessons"`
+}
+
+var progress *ProgressStore
+
+// loadProgress opens the progress database of the current workspace,
+// importing the lesson URL from a legacy .last file if there is one.
+func loadProgress() (*ProgressStore, error) {
+	s := &ProgressStore{
+		path:    filepath.Join(stateDir(), "progress.json"),
+		Lessons: map[string]*LessonProgress{},
+	}
+	b, err := os.ReadFile(s.path)
+	if err == nil {
+		if err := json.Unmarshal(b, s); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", s.path, err)
+		}
+		if s.Lessons == nil {
+			s.Lessons = map[string]*LessonProgress{}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if s.LastLesson == "" {
+		if last, err := os.ReadFile(workspacePath(".last")); err == nil {
+			s.LastLesson = string(last)
+			if err := s.save(); err != nil {
+				return nil, err
+			}
+			os.Remove(workspacePath(".last"))
+		}
+	}
+	return s, nil
+}
+
+// save must be called with mu held, or before the store is shared.
+func (s *ProgressStore) save() error {
+	if err := markWorkspace(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, os.FileMode(cfg.Workspace.FileMode)); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Lesson returns a copy of the progress of uuid. Unknown lessons are
+// reported as not started.
+func (s *ProgressStore) Lesson(uuid string) LessonProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.Lessons[uuid]; ok {
+		return *l
+	}
+	return LessonProgress{UUID: uuid, Status: StatusNotStarted}
+}
+
+// All returns a copy of every recorded lesson.
+func (s *ProgressStore) All() []LessonProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lessons := make([]LessonProgress, 0, len(s.Lessons))
+	for _, l := range s.Lessons {
+		lessons = append(lessons, *l)
+	}
+	return lessons
+}
+
+func (s *ProgressStore) Last() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.LastLesson
+}
+
+func (s *ProgressStore) SetLast(lessonURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.LastLesson = lessonURL
+	return s.save()
+}
+
+// Update applies fn to the progress of uuid and saves the store.
+func (s *ProgressStore) Update(uuid string, fn func(*LessonProgress)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.Lessons[uuid]
+	if !ok {
+		l = &LessonProgress{UUID: uuid, Status: StatusNotStarted}
+		s.Lessons[uuid] = l
+	}
+	fn(l)
+	return s.save()
+}
+
+func (s *ProgressStore) Reset(uuid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Lessons, uuid)
+	return s.save()
+}
+
+// enterState records the active time and the outcome of entering m.state.
+func (m *Model) enterState() {
+	tracker.touch(time.Now())
+	if err := tracker.flush(); err != nil {
+		m.err = fmt.Errorf("failed to save progress: %v", err)
+		m.state = Failed
+	}
+	if err := m.recordProgress(); err != nil {
+		m.err = fmt.Errorf("failed to save progress: %v", err)
+		m.state = Failed
+	}
+}
+
+// recordProgress stores the outcome of entering m.state for the current
+// lesson.
+func (m Model) recordProgress() error {
+	switch m.state {
+	case EditorStart:
+		return m.record(false, false, false)
+	case QuestionCorrect:
+		return m.record(true, true, false)
+	case QuestionFailed:
+		if err := m.recordWrongAnswer(); err != nil {
+			return err
+		}
+		return m.record(true, false, true)
+	case CodeTestSuccess, OutputSuccess, CLIDone, InputSuccess:
+		if err := m.saveAttempt(StatusPassed, ""); err != nil {
+			return err
+		}
+		return m.record(true, true, false)
+	case CodeTestFailed, OutputFail, CLIFailed, InputFail:
+		if err := m.saveAttempt(StatusFailed, ""); err != nil {
+			return err
+		}
+		return m.record(true, false, true)
+	case QuestionRetry:
+		if err := m.recordWrongAnswer(); err != nil {
+			return err
+		}
+		return m.record(true, false, false)
+	}
+	return nil
+}
+
+// markPassed records a lesson that has no check, such as a manual lesson,
+// as passed.
+func (m Model) markPassed() error {
+	return m.record(false, true, false)
+}
+
+// recordWrongAnswer counts the quiz answer just picked as wrong.
+func (m Model) recordWrongAnswer() error {
+	uuid := m.response.Lesson.UUID
+	if uuid == "" || progress == nil || m.quiz.picked == "" {
+		return nil
+	}
+	return progress.Update(uuid, func(l *LessonProgress) {
+		if l.WrongAnswers == nil {
+			l.WrongAnswers = map[string]int{}
+		}
+		l.WrongAnswers[m.quiz.picked]++
+	})
+}
+
+func (m Model) record(attempt, passed, failed bool) error {
+	lesson := m.response.Lesson
+	if lesson.UUID == "" || progress == nil {
+		return nil
+	}
+
+	now := time.Now()
+	return progress.Update(lesson.UUID, func(l *LessonProgress) {
+		l.Slug = lesson.Slug
+		l.Title = lesson.Title
+		l.Type = lesson.Type
+		l.CourseUUID = lesson.CourseUUID
+		l.CourseSlug = lesson.CourseSlug
+		l.CourseTitle = lesson.CourseTitle
+		l.ChapterUUID = lesson.ChapterUUID
+		l.ChapterSlug = lesson.ChapterSlug
+		l.ChapterTitle = lesson.ChapterTitle
+		if l.StartedAt.IsZero() {
+			l.StartedAt = now
+		}
+		if l.Status == StatusNotStarted {
+			l.Status = StatusAttempted
+		}
+		if attempt {
+			l.Attempts++
+			l.LastAttempt = now
+			l.LastOutput = m.content
+		}
+		switch {
+		case passed:
+			if l.CompletedAt.IsZero() {
+				l.CompletedAt = now
+			}
+			l.Status = StatusPassed
+		case failed && l.Status != StatusPassed:
+			l.Status = StatusFailed
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pushRetryInterval is how often queued pushes are retried while the TUI
+// is running.
+const pushRetryInterval = 2 * time.Minute
+
+// PendingPush is a git push that failed, usually for lack of a network, and
+// is retried later.
+type PendingPush struct {
+	Args      []string  `json:"Args"`
+	Queued    time.Time `json:"Queued"`
+	Tries     int       `json:"Tries"`
+	LastError string    `json:"LastError"`
+}
+
+// PushQueue holds the pushes that failed, in .bootdev-local/push-queue.json,
+// until a retry gets them through. mu guards Pushes and the file; the pushes
+// themselves are serialized by pushing.
+type PushQueue struct {
+	mu     sync.Mutex
+	path   string
+	Pushes []PendingPush `json:"Pushes"`
+}
+
+var pushQueue *PushQueue
+
+// pushing is held while git pushes, so a background retry and a lesson's
+// push never run on the repository at once.
+var pushing sync.Mutex
+
+// gitPushCmd is gitCmd for git push. Unless interactive, git and ssh fail
+// instead of prompting for credentials or host keys, which would write over
+// the TUI.
+func gitPushCmd(interactive bool, args ...string) *exec.Cmd {
+	cmd := gitCmd(args...)
+	if interactive {
+		return cmd
+	}
+	ssh := os.Getenv("GIT_SSH_COMMAND")
+	if ssh == "" {
+		out, _ := gitCmd("config", "--get", "core.sshCommand").Output()
+		ssh = strings.TrimSpace(string(out))
+	}
+	if ssh == "" {
+		ssh = "ssh"
+	}
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND="+ssh+" -o BatchMode=yes")
+	return cmd
+}
+
+func loadPushQueue() (*PushQueue, error) {
+	q := &PushQueue{path: filepath.Join(stateDir(), "push-queue.json")}
+	b, err := os.ReadFile(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, q); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", q.path, err)
+	}
+	return q, nil
+}
+
+// save must be called with mu held, or before the queue is shared.
+func (q *PushQueue) save() error {
+	if err := markWorkspace(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, b, os.FileMode(cfg.Workspace.FileMode)); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
+
+func (q *PushQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.Pushes)
+}
+
+func (q *PushQueue) find(args []string) int {
+	return slices.IndexFunc(q.Pushes, func(p PendingPush) bool { return slices.Equal(p.Args, args) })
+}
+
+// Add queues a push of git args that failed with pushErr. Identical pushes
+// are queued once.
+func (q *PushQueue) Add(args []string, pushErr error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := q.find(args)
+	if i < 0 {
+		q.Pushes = append(q.Pushes, PendingPush{Args: args, Queued: time.Now()})
+		i = len(q.Pushes) - 1
+	}
+	q.Pushes[i].Tries++
+	q.Pushes[i].LastError = pushErr.Error()
+	return q.save()
+}
+
+// Remove drops the push of git args from the queue, if it is queued.
+func (q *PushQueue) Remove(args []string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := q.find(args)
+	if i < 0 {
+		return nil
+	}
+	q.Pushes = slices.Delete(q.Pushes, i, i+1)
+	return q.save()
+}
+
+// Flush retries every queued push, writing the git output to w, and returns
+// how many are still pending. Only interactive pushes may prompt.
+func (q *PushQueue) Flush(w io.Writer, interactive bool) (int, error) {
+	pushing.Lock()
+	defer pushing.Unlock()
+	q.mu.Lock()
+	pushes := slices.Clone(q.Pushes)
+	q.mu.Unlock()
+
+	for _, push := range pushes {
+		fmt.Fprintf(w, "󰣇 ❯ git %s\n", strings.Join(push.Args, " "))
+		cmd := gitPushCmd(interactive, push.Args...)
+		cmd.Stdout = w
+		cmd.Stderr = w
+		var err error
+		if runErr := cmd.Run(); runErr != nil {
+			err = q.Add(push.Args, runErr)
+		} else {
+			err = q.Remove(push.Args)
+		}
+		if err != nil {
+			return q.Len(), err
+		}
+	}
+	return q.Len(), nil
+}
+
+// pushStep runs git push with args, queueing it for later if it fails.
+func pushStep(args []string) Step {
+	step := cmdStep(gitPushCmd(false, args...))
+	run := step.Run
+	step.Run = func(w io.Writer) error {
+		pushing.Lock()
+		err := run(w)
+		pushing.Unlock()
+		if err == nil {
+			return pushQueue.Remove(args)
+		}
+		if qErr := pushQueue.Add(args, err); qErr != nil {
+			return qErr
+		}
+		fmt.Fprintf(w, "push queued, it will be retried later (%d pending)\n", pushQueue.Len())
+		return err
+	}
+	step.ContinueOnError = true
+	return step
+}
+
+type (
+	pushQueueMsg struct {
+		pending int
+		err     error
+	}
+	pushRetryMsg struct{}
+)
+
+// syncPushes retries the queued pushes in the background of the TUI.
+func syncPushes() tea.Msg {
+	if pushQueue.Len() == 0 {
+		return pushQueueMsg{}
+	}
+	pending, err := pushQueue.Flush(io.Discard, false)
+	return pushQueueMsg{pending: pending, err: err}
+}
+
+func pushRetryTick() tea.Cmd {
+	return tea.Tick(pushRetryInterval, func(time.Time) tea.Msg { return pushRetryMsg{} })
+}
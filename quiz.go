@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// Quizzes come in two shapes: LessonDataMultipleChoice lists the answers,
+// while LessonDataChoice has them in a single string. quiz turns either into
+// a MultipleChoiceData, which is all the quiz screens read.
+
+// answerMarker is a markdown list marker in front of a string-encoded answer.
+var answerMarker = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+
+// quiz returns the lesson's question, answers and README, from whichever
+// schema the lesson uses.
+func (r *Response) quiz() MultipleChoiceData {
+	mc := r.Lesson.LessonDataMultipleChoice
+	if mc.Question.Question != "" || len(mc.Question.Answers) > 0 {
+		return mc
+	}
+	c := r.Lesson.LessonDataChoice
+	answers := parseAnswers(c.Question.Answers)
+	return MultipleChoiceData{
+		Readme: c.Readme,
+		Question: Question{
+			Question: c.Question.Question,
+			Answers:  answers,
+			Answer:   matchAnswer(c.Question.Answer, answers),
+		},
+	}
+}
+
+// parseAnswers splits string-encoded answers, given either as a JSON array
+// or one per line. Lines are taken as a markdown list when all of them start
+// with a list marker.
+func parseAnswers(s string) []string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		var answers []string
+		if err := json.Unmarshal([]byte(s), &answers); err == nil {
+			return answers
+		}
+	}
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	for _, line := range lines {
+		if !answerMarker.MatchString(line) {
+			return lines
+		}
+	}
+	for i, line := range lines {
+		lines[i] = answerMarker.ReplaceAllString(line, "")
+	}
+	return lines
+}
+
+// matchAnswer returns the answer among answers that the correct answer
+// names, ignoring surrounding space, case and a list marker, so it can be
+// compared with the chosen one.
+func matchAnswer(answer string, answers []string) string {
+	normalize := func(s string) string {
+		return strings.ToLower(answerMarker.ReplaceAllString(strings.TrimSpace(s), ""))
+	}
+	for _, a := range answers {
+		if a == answer {
+			return a
+		}
+	}
+	for _, a := range answers {
+		if normalize(a) == normalize(answer) {
+			return a
+		}
+	}
+	return answer
+}
+
+// quizState is the progress through the current quiz.
+type quizState struct {
+	// answers are the quiz's answers in the order they are shown.
+	answers []string
+	// picked is the last answer picked.
+	picked string
+	// wrong are the wrong answers picked so far.
+	wrong []string
+}
+
+// maxAttempts is the number of answers allowed per quiz, or 0 for no limit.
+func (q QuizConfig) maxAttempts() int {
+	if q.Exam {
+		return 1
+	}
+	return q.Attempts
+}
+
+// startQuiz shows the quiz's answers, shuffled if configured.
+func (m *Model) startQuiz() {
+	answers := slices.Clone(m.response.quiz().Question.Answers)
+	if cfg.Quiz.Shuffle {
+		rand.Shuffle(len(answers), func(i, j int) { answers[i], answers[j] = answers[j], answers[i] })
+	}
+	m.quiz = quizState{answers: answers}
+	m.attempts = 0
+	m.state = QuestionStart
+	m.list = m.answerList()
+	m.quizReadme()
+}
+
+// answerList lists the answers, marking the wrong ones already picked.
+func (m Model) answerList() list.Model {
+	items := make([]list.Item, len(m.quiz.answers))
+	for i, answer := range m.quiz.answers {
+		var marker string
+		switch {
+		case slices.Contains(m.quiz.wrong, answer):
+			marker = statusMarker(StatusFailed)
+		case len(m.quiz.wrong) > 0:
+			// blank, to keep the answers aligned
+			marker = statusMarker(StatusNotStarted)
+		}
+		items[i] = item{title: answer, marker: marker}
+	}
+	return m.newList(items, ItemDelegate{})
+}
+
+// answerQuiz grades the selected answer.
+func (m *Model) answerQuiz() {
+	selected := m.quiz.answers[index(m.list)]
+	m.quiz.picked = selected
+	if selected == m.response.quiz().Question.Answer {
+		m.state = QuestionCorrect
+		return
+	}
+	m.attempts++
+	if !slices.Contains(m.quiz.wrong, selected) {
+		m.quiz.wrong = append(m.quiz.wrong, selected)
+	}
+	if limit := cfg.Quiz.maxAttempts(); limit > 0 && m.attempts >= limit {
+		m.state = QuestionFailed
+		m.content = renderMarkdown(m.quizReview(), m.width)
+		m.viewport = m.updateViewport()
+		m.viewport.GotoTop()
+		return
+	}
+	m.state = QuestionRetry
+	cursor := index(m.list)
+	m.list = m.answerList()
+	m.list.Select(cursor)
+	m.quizReadme()
+}
+
+// quizReview explains a failed quiz: the correct answer, the wrong ones
+// picked, and the part of the README about the question.
+func (m Model) quizReview() string {
+	quiz := m.response.quiz()
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\nThe correct answer was **%s**.\n\n", quiz.Question.Question, quiz.Question.Answer)
+	if len(m.quiz.wrong) > 0 {
+		b.WriteString("You picked:\n\n")
+		for _, answer := range m.quiz.wrong {
+			fmt.Fprintf(&b, "- ~~%s~~\n", answer)
+		}
+		b.WriteString("\n")
+	}
+	if section := relevantSection(quiz.Readme, quiz.Question.Question+" "+quiz.Question.Answer); section != "" {
+		fmt.Fprintf(&b, "---\n\n%s\n", section)
+	}
+	return b.String()
+}
+
+// relevantSection returns the section of readme that best matches query,
+// scoring each section by the query words it contains, weighted by how few
+// other sections contain them. READMEs without headings are split into
+// paragraphs instead. The whole README is returned if nothing stands out.
+func relevantSection(readme, query string) string {
+	chunks := splitReadme(readme, true)
+	if len(chunks) < 2 {
+		chunks = splitReadme(readme, false)
+	}
+	if len(chunks) < 2 {
+		return strings.TrimSpace(readme)
+	}
+
+	words := map[string]bool{}
+	for _, word := range tokenize(query) {
+		if len([]rune(word)) >= 3 {
+			words[word] = true
+		}
+	}
+	chunkWords := make([]map[string]bool, len(chunks))
+	docFreq := map[string]int{}
+	for i, chunk := range chunks {
+		chunkWords[i] = map[string]bool{}
+		for _, word := range tokenize(chunk) {
+			if words[word] && !chunkWords[i][word] {
+				chunkWords[i][word] = true
+				docFreq[word]++
+			}
+		}
+	}
+	best, bestScore := -1, 0.0
+	for i := range chunks {
+		score := 0.0
+		for word := range chunkWords[i] {
+			score += math.Log(float64(len(chunks)) / float64(docFreq[word]))
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return strings.TrimSpace(readme)
+	}
+	return chunks[best]
+}
+
+// splitReadme splits markdown at its headings, or at blank lines, leaving
+// code blocks whole.
+func splitReadme(readme string, headings bool) []string {
+	var chunks []string
+	var chunk strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(chunk.String()); text != "" {
+			chunks = append(chunks, text)
+		}
+		chunk.Reset()
+	}
+	fenced := false
+	for _, line := range strings.Split(readme, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+		}
+		if !fenced {
+			if headings && strings.HasPrefix(trimmed, "#") {
+				flush()
+			} else if !headings && trimmed == "" {
+				flush()
+			}
+		}
+		chunk.WriteString(line + "\n")
+	}
+	flush()
+	return chunks
+}
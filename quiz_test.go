@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestResponseQuiz(t *testing.T) {
+	tests := []struct {
+		name     string
+		lesson   string
+		question string
+		answers  []string
+		answer   string
+	}{
+		{
+			name: "multiple choice",
+			lesson: `{"LessonDataMultipleChoice": {"Readme": "# Slices", "Question": {
+				"Question": "What does append return?",
+				"Answers": ["A new slice, possibly sharing the array", "Nothing, it appends in place", "An error,\nif the slice is full"],
+				"Answer": "A new slice, possibly sharing the array"}}}`,
+			question: "What does append return?",
+			answers:  []string{"A new slice, possibly sharing the array", "Nothing, it appends in place", "An error,\nif the slice is full"},
+			answer:   "A new slice, possibly sharing the array",
+		},
+		{
+			name: "choice with a JSON array",
+			lesson: `{"LessonDataChoice": {"Readme": "# Maps", "Question": {
+				"Question": "Which are valid map keys?",
+				"Answers": "[\"int, string and structs of them\", \"slices\", \"funcs,\\nmaps and slices\"]",
+				"Answer": "int, string and structs of them"}}}`,
+			question: "Which are valid map keys?",
+			answers:  []string{"int, string and structs of them", "slices", "funcs,\nmaps and slices"},
+			answer:   "int, string and structs of them",
+		},
+		{
+			name: "choice with a markdown list",
+			lesson: `{"LessonDataChoice": {"Question": {
+				"Question": "Which is true?",
+				"Answers": "- Yes, always\n- No, never\n\n- It depends, on the compiler\n",
+				"Answer": "- yes, always"}}}`,
+			question: "Which is true?",
+			answers:  []string{"Yes, always", "No, never", "It depends, on the compiler"},
+			answer:   "Yes, always",
+		},
+		{
+			name: "choice with a numbered list",
+			lesson: `{"LessonDataChoice": {"Question": {
+				"Question": "Pick one",
+				"Answers": "1. first, of all\r\n2) second",
+				"Answer": "second"}}}`,
+			question: "Pick one",
+			answers:  []string{"first, of all", "second"},
+			answer:   "second",
+		},
+		{
+			name: "choice with plain lines",
+			lesson: `{"LessonDataChoice": {"Question": {
+				"Question": "What is printed?",
+				"Answers": "-1\n0\n1, 2",
+				"Answer": "1, 2"}}}`,
+			question: "What is printed?",
+			answers:  []string{"-1", "0", "1, 2"},
+			answer:   "1, 2",
+		},
+		{
+			name: "choice with a bad JSON array",
+			lesson: `{"LessonDataChoice": {"Question": {
+				"Question": "Broken?",
+				"Answers": "[yes, no]\nmaybe",
+				"Answer": "maybe"}}}`,
+			question: "Broken?",
+			answers:  []string{"[yes, no]", "maybe"},
+			answer:   "maybe",
+		},
+	}
+	for _, tt := range tests {
+		var r Response
+		if err := json.Unmarshal([]byte(`{"Lesson": `+tt.lesson+`}`), &r); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		quiz := r.quiz()
+		if quiz.Question.Question != tt.question {
+			t.Errorf("%s: question = %q, want %q", tt.name, quiz.Question.Question, tt.question)
+		}
+		if !slices.Equal(quiz.Question.Answers, tt.answers) {
+			t.Errorf("%s: answers = %q, want %q", tt.name, quiz.Question.Answers, tt.answers)
+		}
+		if quiz.Question.Answer != tt.answer {
+			t.Errorf("%s: answer = %q, want %q", tt.name, quiz.Question.Answer, tt.answer)
+		}
+		if !slices.Contains(quiz.Question.Answers, quiz.Question.Answer) {
+			t.Errorf("%s: answer %q is not among the answers", tt.name, quiz.Question.Answer)
+		}
+	}
+}
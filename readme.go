@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var searchMatchStyle = lipgloss.NewStyle().Reverse(true)
+
+// readmeViewer holds the state of the README screen and the README shown
+// above quiz answers.
+type readmeViewer struct {
+	returnState   State
+	returnContent string
+	// rendered is the README as rendered for the current width.
+	rendered  string
+	search    textinput.Model
+	searching bool
+	query     string
+	matches   []int
+	match     int
+	quiz      viewport.Model
+}
+
+// renderMarkdown renders md with glamour, wrapped to width columns. The
+// markdown is returned as is if it cannot be rendered.
+func renderMarkdown(md string, width int) string {
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(cfg.UI.MarkdownStyle),
+		glamour.WithWordWrap(max(20, width-4)),
+	)
+	if err != nil {
+		return md
+	}
+	out, err := r.Render(md)
+	if err != nil {
+		return md
+	}
+	return out
+}
+
+// lessonReadme returns the lesson's README. For quizzes this is the lesson
+// text without the question, which the answer list shows.
+func (m Model) lessonReadme() string {
+	if m.response.Lesson.Type == "type_choice" {
+		return m.response.quiz().Readme
+	}
+	_, readme, err := m.lessonFiles()
+	if err != nil {
+		return ""
+	}
+	return readme
+}
+
+// openReadme switches to the README screen, returning to the current state
+// when it is closed.
+func (m *Model) openReadme() {
+	m.readme.returnState = m.state
+	m.readme.returnContent = m.content
+	m.readme.rendered = renderMarkdown(m.lessonReadme(), m.width)
+	m.readme.query = ""
+	m.readme.matches = nil
+	m.state = ReadmeView
+	m.title = "README: " + m.response.Lesson.Title
+	m.content = m.readme.rendered
+	m.viewport = m.updateViewport()
+	m.viewport.GotoTop()
+}
+
+func (m *Model) closeReadme() {
+	m.state = m.readme.returnState
+	m.content = m.readme.returnContent
+	m.readme.searching = false
+	m.viewport = m.updateViewport()
+}
+
+func (m *Model) startSearch() {
+	m.readme.search = textinput.New()
+	m.readme.search.Prompt = "/"
+	m.readme.search.SetValue(m.readme.query)
+	m.readme.search.Focus()
+	m.readme.searching = true
+}
+
+// findMatches searches the rendered README for the query, case-insensitively,
+// and shows the first match below the current position.
+func (m *Model) findMatches() {
+	m.readme.query = m.readme.search.Value()
+	m.readme.searching = false
+	m.readme.matches = nil
+	query := strings.ToLower(m.readme.query)
+	if query == "" {
+		m.showMatch(0)
+		return
+	}
+	for i, line := range strings.Split(m.readme.rendered, "\n") {
+		if strings.Contains(strings.ToLower(ansi.Strip(line)), query) {
+			m.readme.matches = append(m.readme.matches, i)
+		}
+	}
+	first := 0
+	for i, line := range m.readme.matches {
+		if line >= m.viewport.YOffset {
+			first = i
+			break
+		}
+	}
+	m.showMatch(first)
+}
+
+// showMatch highlights match i, wrapping around at either end, and scrolls
+// it into view.
+func (m *Model) showMatch(i int) {
+	lines := strings.Split(m.readme.rendered, "\n")
+	m.title = "README: " + m.response.Lesson.Title
+	if len(m.readme.matches) == 0 {
+		if m.readme.query != "" {
+			m.title += fmt.Sprintf(" (no match for %q)", m.readme.query)
+		}
+		m.viewport.SetContent(m.readme.rendered)
+		return
+	}
+	i = (i + len(m.readme.matches)) % len(m.readme.matches)
+	m.readme.match = i
+	line := m.readme.matches[i]
+	lines[line] = searchMatchStyle.Render(ansi.Strip(lines[line]))
+	m.title += fmt.Sprintf(" (%d/%d)", i+1, len(m.readme.matches))
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+	m.viewport.SetYOffset(max(0, line-m.viewport.Height/3))
+}
+
+// quizReadme sizes the README shown above the quiz answers, leaving room
+// for the answer list.
+func (m *Model) quizReadme() {
+	readme := m.lessonReadme()
+	if readme == "" || cfg.Quiz.Exam {
+		m.readme.quiz = viewport.Model{}
+		return
+	}
+	answers := len(m.response.quiz().Question.Answers)
+	// the list title, its padding and the help line
+	listHeight := answers*ItemDelegate{}.Height() + 6
+	rendered := renderMarkdown(readme, m.width)
+	height := min(lipgloss.Height(rendered), max(3, m.height-listHeight))
+	m.readme.quiz = viewport.New(m.width, height)
+	m.readme.quiz.SetContent(rendered)
+	m.list.SetSize(m.width, m.height-height)
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Lesson search covers every lesson in the workspace cache, which holds each
+// lesson that was opened or downloaded.
+
+const lessonCachePrefix = "static_lessons_"
+
+// Fields of a lesson are weighted by how much a match in them says about the
+// lesson.
+var searchWeights = map[string]float64{
+	"title":    5,
+	"question": 3,
+	"readme":   2,
+	"code":     1,
+}
+
+type searchDoc struct {
+	UUID    string
+	Title   string
+	Course  string
+	Chapter string
+	fields  map[string]string
+	// terms counts each term's weighted occurrences over the fields.
+	terms map[string]float64
+}
+
+type searchIndex struct {
+	docs []*searchDoc
+	// docFreq is the number of lessons each term occurs in.
+	docFreq map[string]int
+}
+
+type searchResult struct {
+	doc     *searchDoc
+	score   float64
+	snippet string
+}
+
+// tokenize splits text into lower case words.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// lessonDoc extracts the searchable text of a cached lesson.
+func lessonDoc(res *Response) (*searchDoc, error) {
+	lesson := res.Lesson
+	starterFiles, readme, err := Model{response: res}.lessonFiles()
+	if err != nil {
+		return nil, err
+	}
+	var code strings.Builder
+	for _, f := range starterFiles {
+		if !f.IsHidden {
+			code.WriteString(f.Content + "\n")
+		}
+	}
+	quiz := res.quiz()
+	question := quiz.Question
+	if lesson.Type == "type_choice" {
+		// lessonFiles appends the question to the README
+		readme = quiz.Readme
+	}
+	doc := &searchDoc{
+		UUID:    lesson.UUID,
+		Title:   lesson.Title,
+		Course:  lesson.CourseTitle,
+		Chapter: lesson.ChapterTitle,
+		fields: map[string]string{
+			"title":    lesson.Title,
+			"question": question.Question + "\n" + strings.Join(question.Answers, "\n"),
+			"readme":   readme,
+			"code":     code.String(),
+		},
+		terms: map[string]float64{},
+	}
+	for field, text := range doc.fields {
+		for _, term := range tokenize(text) {
+			doc.terms[term] += searchWeights[field]
+		}
+	}
+	return doc, nil
+}
+
+// loadSearchIndex indexes the lessons in the workspace cache.
+func loadSearchIndex() (*searchIndex, error) {
+	ix := &searchIndex{docFreq: map[string]int{}}
+	if workspace == "" {
+		return ix, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(cacheDir(), lessonCachePrefix+"*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var res Response
+		if err := json.Unmarshal(body, &res); err != nil || res.Lesson.UUID == "" {
+			continue
+		}
+		doc, err := lessonDoc(&res)
+		if err != nil {
+			continue
+		}
+		ix.docs = append(ix.docs, doc)
+		for term := range doc.terms {
+			ix.docFreq[term]++
+		}
+	}
+	return ix, nil
+}
+
+// search ranks the lessons containing every word of query, the last of which
+// may be the start of a word, by TF-IDF over the weighted fields. Lessons
+// whose title contains the whole query come first.
+func (ix *searchIndex) search(query string, limit int) []searchResult {
+	words := tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+	var results []searchResult
+	for _, doc := range ix.docs {
+		score := 0.0
+		for i, word := range words {
+			matched := 0.0
+			for term, weight := range doc.terms {
+				if term == word || (i == len(words)-1 && strings.HasPrefix(term, word)) {
+					idf := math.Log(1 + float64(len(ix.docs))/float64(ix.docFreq[term]))
+					matched += (1 + math.Log(weight)) * idf
+				}
+			}
+			if matched == 0 {
+				score = 0
+				break
+			}
+			score += matched
+		}
+		if score == 0 {
+			continue
+		}
+		if strings.Contains(strings.ToLower(doc.Title), strings.ToLower(strings.TrimSpace(query))) {
+			score *= 2
+		}
+		results = append(results, searchResult{doc: doc, score: score, snippet: doc.snippet(words)})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// snippet returns the text around the first match of words, looking in the
+// question, README and code in turn.
+func (d *searchDoc) snippet(words []string) string {
+	for _, field := range []string{"question", "readme", "code"} {
+		runes := []rune(strings.Join(strings.Fields(d.fields[field]), " "))
+		// lowered rune by rune, so positions match runes
+		lower := string(mapRunes(runes, unicode.ToLower))
+		for _, word := range words {
+			i := strings.Index(lower, word)
+			if i < 0 {
+				continue
+			}
+			start := max(0, utf8.RuneCountInString(lower[:i])-30)
+			end := min(len(runes), start+120)
+			snippet := string(runes[start:end])
+			if start > 0 {
+				snippet = "…" + snippet
+			}
+			if end < len(runes) {
+				snippet += "…"
+			}
+			return snippet
+		}
+	}
+	return ""
+}
+
+func mapRunes(runes []rune, f func(rune) rune) []rune {
+	mapped := make([]rune, len(runes))
+	for i, r := range runes {
+		mapped[i] = f(r)
+	}
+	return mapped
+}
+
+// lessonSearch is the state of the lesson search screen.
+type lessonSearch struct {
+	returnState   State
+	returnContent string
+	returnList    list.Model
+	input         textinput.Model
+	index         *searchIndex
+	results       []searchResult
+}
+
+type searchIndexMsg struct {
+	index *searchIndex
+	err   error
+}
+
+func (m *Model) openSearch() tea.Cmd {
+	m.search = lessonSearch{returnState: m.state, returnContent: m.content, returnList: m.list}
+	m.search.input = textinput.New()
+	m.search.input.Placeholder = "search cached lessons"
+	m.search.input.Prompt = "🔍 "
+	m.search.input.Focus()
+	m.state = LessonSearch
+	m.list = m.searchList()
+	return tea.Batch(textinput.Blink, func() tea.Msg {
+		ix, err := loadSearchIndex()
+		return searchIndexMsg{index: ix, err: err}
+	})
+}
+
+func (m *Model) closeSearch() {
+	m.state = m.search.returnState
+	m.content = m.search.returnContent
+	m.list = m.search.returnList
+	// the window may have been resized meanwhile
+	m.list.SetSize(m.width, m.height)
+	switch m.state {
+	case QuestionStart:
+		m.quizReadme()
+	case CourseOverview:
+		m.layoutOverview()
+	}
+	m.viewport = m.updateViewport()
+}
+
+// runSearch searches for the current input and lists the results.
+func (m *Model) runSearch() {
+	m.search.results = nil
+	if m.search.index != nil {
+		m.search.results = m.search.index.search(m.search.input.Value(), 100)
+	}
+	m.list = m.searchList()
+}
+
+func (m Model) searchList() list.Model {
+	items := make([]list.Item, len(m.search.results))
+	for i, r := range m.search.results {
+		desc := fmt.Sprintf("%s › %s", r.doc.Course, r.doc.Chapter)
+		if r.snippet != "" {
+			desc += "  " + r.snippet
+		}
+		items[i] = item{title: r.doc.Title, desc: desc}
+	}
+	l := m.newList(items, ItemDelegate{showDescription: true})
+	// the input and help line take the rest
+	l.SetSize(m.width, max(0, m.height-4))
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+	switch {
+	case m.search.index == nil:
+		l.Title = "Indexing lessons…"
+	case len(m.search.index.docs) == 0:
+		l.Title = "No cached lessons, open or download some first"
+	case m.search.input.Value() == "":
+		l.Title = fmt.Sprintf("Search %d lessons", len(m.search.index.docs))
+	default:
+		l.Title = fmt.Sprintf("%d results", len(m.search.results))
+	}
+	return l
+}
+
+// openResult opens the selected lesson.
+func (m *Model) openResult() tea.Cmd {
+	if len(m.search.results) == 0 {
+		return nil
+	}
+	m.setTarget(Target{Kind: TargetLesson, ID: m.search.results[index(m.list)].doc.UUID})
+	m.state = Fetch
+	return m.fetchLesson
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ChapterStats struct {
+	Title  string
+	Passed int
+	Total  int
+}
+
+type CourseStats struct {
+	UUID      string
+	Title     string
+	Passed    int
+	Total     int
+	Chapters  []ChapterStats
+	Spent     time.Duration
+	Estimate  time.Duration
+	XP        int
+	Completed bool
+}
+
+type TrackStats struct {
+	Title  string
+	Passed int
+	Total  int
+}
+
+// Stats summarises local progress against the course metadata.
+type Stats struct {
+	Tracks      []TrackStats
+	Courses     []CourseStats
+	Passed      int
+	PassedToday int
+	PassedWeek  int
+	AvgAttempts float64
+	Spent       time.Duration
+	// Activity counts lessons passed per day, keyed by "2006-01-02".
+	Activity map[string]int
+}
+
+type statsMsg struct {
+	stats Stats
+}
+
+// loadStats builds Stats from the progress store, fetching course and
+// track metadata through the cache.
+func loadStats() Stats {
+	now := time.Now()
+	today := now.Format(time.DateOnly)
+	weekAgo := now.AddDate(0, 0, -7)
+
+	stats := Stats{Activity: map[string]int{}}
+	byCourse := map[string][]LessonProgress{}
+	courseSlugs := map[string]string{}
+	attempts := 0
+	for _, l := range progress.All() {
+		byCourse[l.CourseUUID] = append(byCourse[l.CourseUUID], l)
+		courseSlugs[l.CourseUUID] = l.CourseSlug
+		stats.Spent += l.ActiveTime
+		if l.Status != StatusPassed {
+			continue
+		}
+		stats.Passed++
+		attempts += l.Attempts
+		day := l.CompletedAt.Format(time.DateOnly)
+		stats.Activity[day]++
+		if day == today {
+			stats.PassedToday++
+		}
+		if l.CompletedAt.After(weekAgo) {
+			stats.PassedWeek++
+		}
+	}
+	if stats.Passed > 0 {
+		stats.AvgAttempts = float64(attempts) / float64(stats.Passed)
+	}
+
+	courseTotals := map[string]int{}
+	for uuid, lessons := range byCourse {
+		cs := CourseStats{UUID: uuid, Title: lessons[0].CourseTitle}
+		passedByChapter := map[string]int{}
+		for _, l := range lessons {
+			cs.Spent += l.ActiveTime
+			if l.Status == StatusPassed {
+				cs.Passed++
+				passedByChapter[l.ChapterUUID]++
+			}
+		}
+
+		if res, ok := cachedRequest[Response](COURSE_URL + courseSlugs[uuid]).(*Response); ok {
+			course := res.Course
+			cs.Title = course.Title
+			cs.Total = course.NumLessons
+			cs.Estimate = time.Duration(course.EstimatedCompletionTimeHours) * time.Hour
+			cs.XP = course.CompletionXp
+			for _, chap := range course.Chapters {
+				cs.Chapters = append(cs.Chapters, ChapterStats{
+					Title:  chap.Title,
+					Passed: passedByChapter[chap.UUID],
+					Total:  chap.NumLessons,
+				})
+			}
+		}
+		cs.Total = max(cs.Total, len(lessons))
+		cs.Completed = cs.Passed == cs.Total
+		courseTotals[uuid] = cs.Total
+		stats.Courses = append(stats.Courses, cs)
+	}
+	sort.Slice(stats.Courses, func(i, j int) bool { return stats.Courses[i].Title < stats.Courses[j].Title })
+
+	if tracks, ok := cachedRequest[TracksResponse](TRACKS_URL).(*TracksResponse); ok {
+		for _, track := range *tracks {
+			ts := TrackStats{Title: track.Title}
+			touched := false
+			for _, course := range track.Courses {
+				if _, ok := byCourse[course.UUID]; ok {
+					touched = true
+				}
+			}
+			if !touched {
+				continue
+			}
+			for _, course := range track.Courses {
+				total, ok := courseTotals[course.UUID]
+				if !ok && course.Slug != "" {
+					if res, ok := cachedRequest[Response](COURSE_URL + course.Slug).(*Response); ok {
+						total = res.Course.NumLessons
+					}
+					courseTotals[course.UUID] = total
+				}
+				ts.Total += total
+				for _, l := range byCourse[course.UUID] {
+					if l.Status == StatusPassed {
+						ts.Passed++
+					}
+				}
+			}
+			stats.Tracks = append(stats.Tracks, ts)
+		}
+	}
+	return stats
+}
+
+func percent(passed, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(passed) / float64(total) * 100
+}
+
+func progressBar(passed, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(width, passed*width/total)
+	}
+	return correctStyle.Render(strings.Repeat("█", filled)) + strings.Repeat("░", width-filled)
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// render formats the dashboard for a terminal width columns wide.
+func (s Stats) render(width int) string {
+	var b strings.Builder
+	heading := titleStyle.UnsetMarginLeft()
+
+	fmt.Fprintf(&b, "%s\n", heading.Render("Overview"))
+	fmt.Fprintf(&b, "  Lessons passed: %d (today %d, this week %d)\n", s.Passed, s.PassedToday, s.PassedWeek)
+	fmt.Fprintf(&b, "  Average attempts: %.1f\n", s.AvgAttempts)
+	fmt.Fprintf(&b, "  Time spent: %s\n\n", formatDuration(s.Spent))
+
+	if len(s.Tracks) > 0 {
+		fmt.Fprintf(&b, "%s\n", heading.Render("Tracks"))
+		for _, t := range s.Tracks {
+			fmt.Fprintf(&b, "  %s %5.1f%%  %s (%d/%d)\n", progressBar(t.Passed, t.Total, 20), percent(t.Passed, t.Total), t.Title, t.Passed, t.Total)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, c := range s.Courses {
+		fmt.Fprintf(&b, "%s\n", heading.Render(c.Title))
+		fmt.Fprintf(&b, "  %s %5.1f%%  %d/%d lessons\n", progressBar(c.Passed, c.Total, 20), percent(c.Passed, c.Total), c.Passed, c.Total)
+		if c.Estimate > 0 {
+			fmt.Fprintf(&b, "  Time: %s of an estimated %s\n", formatDuration(c.Spent), formatDuration(c.Estimate))
+		} else {
+			fmt.Fprintf(&b, "  Time: %s\n", formatDuration(c.Spent))
+		}
+		if c.XP > 0 {
+			state := "on completion"
+			if c.Completed {
+				state = "earned"
+			}
+			fmt.Fprintf(&b, "  XP: %d %s\n", c.XP, state)
+		}
+		for _, chap := range c.Chapters {
+			if chap.Passed == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "    %s %5.1f%%  %s\n", progressBar(chap.Passed, chap.Total, 10), percent(chap.Passed, chap.Total), chap.Title)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "%s\n", heading.Render("Activity"))
+	b.WriteString(s.heatmap(width))
+	return b.String()
+}
+
+var heatmapLevels = []lipgloss.Style{
+	lipgloss.NewStyle().Foreground(lipgloss.Color("237")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("#0e4429")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("#006d32")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("#26a641")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("#39d353")),
+}
+
+func heatmapLevel(n int) lipgloss.Style {
+	switch {
+	case n == 0:
+		return heatmapLevels[0]
+	case n == 1:
+		return heatmapLevels[1]
+	case n <= 3:
+		return heatmapLevels[2]
+	case n <= 5:
+		return heatmapLevels[3]
+	}
+	return heatmapLevels[4]
+}
+
+// heatmap renders lessons passed per day, one column per week ending with
+// the current one, as on a GitHub profile.
+func (s Stats) heatmap(width int) string {
+	weeks := min(52, max(4, (width-8)/2))
+	now := time.Now()
+	// start on the Sunday weeks-1 weeks ago
+	start := now.AddDate(0, 0, -int(now.Weekday())-7*(weeks-1))
+
+	var b strings.Builder
+	labels := []string{"", "Mon", "", "Wed", "", "Fri", ""}
+	for day := 0; day < 7; day++ {
+		fmt.Fprintf(&b, "  %-4s", labels[day])
+		for week := 0; week < weeks; week++ {
+			date := start.AddDate(0, 0, week*7+day)
+			if date.After(now) {
+				break
+			}
+			b.WriteString(heatmapLevel(s.Activity[date.Format(time.DateOnly)]).Render("■") + " ")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("  Less ")
+	for _, level := range heatmapLevels {
+		b.WriteString(level.Render("■") + " ")
+	}
+	b.WriteString("More\n")
+	return b.String()
+}
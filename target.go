@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type TargetKind int
+
+const (
+	TargetLesson TargetKind = iota
+	TargetCourse
+	TargetTrack
+	// TargetSlug is a bare slug, which names a track or a course. See
+	// Target.resolve.
+	TargetSlug
+)
+
+func (k TargetKind) String() string {
+	return [...]string{"lesson", "course", "track", "slug"}[k]
+}
+
+// Target is what a lesson, course or track argument points at.
+type Target struct {
+	Kind TargetKind
+	// ID is the lesson UUID, or the course or track slug.
+	ID string
+	// Chapter is the chapter anchor of a course URL, a chapter slug, UUID or
+	// 1-based number.
+	Chapter string
+}
+
+// parseTarget recognises lesson UUIDs, Boot.dev lesson, course and track
+// URLs, with or without a scheme, and their API URLs, as well as bare course
+// and track slugs. Query strings, trailing slashes and case are ignored and a
+// course URL's fragment selects a chapter.
+func parseTarget(s string) (Target, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Target{}, fmt.Errorf("no lesson, course or track given")
+	}
+	if uuidPattern.MatchString(s) {
+		return Target{Kind: TargetLesson, ID: strings.ToLower(s)}, nil
+	}
+	if !strings.ContainsAny(s, "/#?") {
+		slug := strings.ToLower(s)
+		if !slugPattern.MatchString(slug) {
+			return Target{}, fmt.Errorf("%q is neither a lesson UUID nor a course or track slug", s)
+		}
+		return Target{Kind: TargetSlug, ID: slug}, nil
+	}
+
+	raw := s
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Target{}, fmt.Errorf("%q is not a valid URL: %v", s, err)
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host != "boot.dev" && host != "api.boot.dev" {
+		return Target{}, fmt.Errorf("%q is not a Boot.dev URL", s)
+	}
+
+	var parts []string
+	for _, part := range strings.Split(u.Path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	// API URLs look like /v1/static/lessons/<uuid> or /v1/static/courses/slug/<slug>
+	if host == "api.boot.dev" {
+		if len(parts) > 0 && parts[0] == "v1" {
+			parts = parts[1:]
+		}
+		if len(parts) > 0 && parts[0] == "static" {
+			parts = parts[1:]
+		}
+		if len(parts) == 3 && parts[0] == "courses" && parts[1] == "slug" {
+			parts = []string{"courses", parts[2]}
+		}
+	}
+	if len(parts) != 2 {
+		return Target{}, fmt.Errorf("%q is not a lesson, course or track URL", s)
+	}
+
+	id := strings.ToLower(parts[1])
+	switch parts[0] {
+	case "lessons":
+		if !uuidPattern.MatchString(id) {
+			return Target{}, fmt.Errorf("%q does not end in a lesson UUID", s)
+		}
+		return Target{Kind: TargetLesson, ID: id}, nil
+	case "courses":
+		if !slugPattern.MatchString(id) {
+			return Target{}, fmt.Errorf("%q does not end in a course slug", s)
+		}
+		return Target{Kind: TargetCourse, ID: id, Chapter: u.Fragment}, nil
+	case "tracks":
+		if !slugPattern.MatchString(id) {
+			return Target{}, fmt.Errorf("%q does not end in a track slug", s)
+		}
+		return Target{Kind: TargetTrack, ID: id}, nil
+	}
+	return Target{}, fmt.Errorf("%q is not a lesson, course or track URL", s)
+}
+
+// resolve decides whether a bare slug names a track or a course by looking
+// it up in the list of tracks, and replaces a course's alias with its slug.
+func (t Target) resolve() Target {
+	if t.Kind == TargetSlug {
+		t.Kind = TargetCourse
+		if tracks, ok := cachedRequest[TracksResponse](TRACKS_URL).(*TracksResponse); ok {
+			for _, track := range *tracks {
+				if track.Slug == t.ID {
+					t.Kind = TargetTrack
+				}
+			}
+		}
+	}
+	if t.Kind == TargetCourse {
+		if slug, ok := courseSlug(t.ID); ok {
+			t.ID = slug
+		}
+	}
+	return t
+}
+
+// courseSlug looks up the slug of the course named by slug or one of its
+// SlugAliases among the courses in the workspace cache. Courses that are not
+// cached are left to the course API, which also resolves aliases.
+func courseSlug(alias string) (string, bool) {
+	if workspace == "" {
+		return "", false
+	}
+	paths, _ := filepath.Glob(filepath.Join(cacheDir(), "static_courses_slug_*.json"))
+	for _, path := range paths {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var res Response
+		if err := json.Unmarshal(body, &res); err != nil || res.Course.Slug == "" {
+			continue
+		}
+		if res.Course.Slug == alias || slices.Contains(res.Course.SlugAliases, alias) {
+			return res.Course.Slug, true
+		}
+	}
+	return "", false
+}
+
+// setTarget points the model at t, which must be resolved.
+func (m *Model) setTarget(t Target) {
+	m.lessonURL, m.courseURL, m.courseProgressURL, m.trackURL = "", "", "", ""
+	m.chapterAnchor = t.Chapter
+	switch t.Kind {
+	case TargetLesson:
+		m.lessonURL = LESSON_URL + t.ID
+	case TargetCourse:
+		m.courseURL = COURSE_URL + t.ID
+	case TargetTrack:
+		m.trackURL = TRACK_URL + t.ID
+	}
+}
+
+// anchoredChapter returns the index of the chapter a course URL's anchor
+// names, or -1.
+func (m Model) anchoredChapter() int {
+	anchor := strings.ToLower(m.chapterAnchor)
+	if anchor == "" || m.courseProgressResponse == nil {
+		return -1
+	}
+	chapters := m.courseProgressResponse.Chapters
+	if n, err := strconv.Atoi(strings.TrimPrefix(anchor, "chapter-")); err == nil && n >= 1 && n <= len(chapters) {
+		return n - 1
+	}
+	uuid := anchor
+	for _, chap := range m.response.Course.Chapters {
+		if chap.Slug == anchor {
+			uuid = chap.UUID
+		}
+	}
+	for i, chap := range chapters {
+		if chap.UUID == uuid {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testUUID = "bb1b1b68-a688-4341-821c-54614ed5eed2"
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		in      string
+		kind    TargetKind
+		id      string
+		chapter string
+	}{
+		// lessons
+		{testUUID, TargetLesson, testUUID, ""},
+		{"  BB1B1B68-A688-4341-821C-54614ED5EED2 ", TargetLesson, testUUID, ""},
+		{"https://www.boot.dev/lessons/" + testUUID, TargetLesson, testUUID, ""},
+		{"https://boot.dev/lessons/" + testUUID, TargetLesson, testUUID, ""},
+		{"www.boot.dev/lessons/" + testUUID, TargetLesson, testUUID, ""},
+		{"http://www.boot.dev/lessons/" + testUUID + "/", TargetLesson, testUUID, ""},
+		{"https://www.boot.dev/lessons/" + testUUID + "?ref=home&tab=solution", TargetLesson, testUUID, ""},
+		{"https://www.boot.dev/lessons/" + testUUID + "#instructions", TargetLesson, testUUID, ""},
+		{"https://api.boot.dev/v1/static/lessons/" + testUUID, TargetLesson, testUUID, ""},
+		// courses and their chapters
+		{"https://www.boot.dev/courses/learn-golang", TargetCourse, "learn-golang", ""},
+		{"https://www.boot.dev/courses/learn-golang/", TargetCourse, "learn-golang", ""},
+		{"https://www.boot.dev/courses/Learn-Golang?utm_source=x", TargetCourse, "learn-golang", ""},
+		{"boot.dev/courses/learn-golang", TargetCourse, "learn-golang", ""},
+		{"https://www.boot.dev/courses/learn-golang#chapter-3", TargetCourse, "learn-golang", "chapter-3"},
+		{"https://www.boot.dev/courses/learn-golang/?a=b#variables", TargetCourse, "learn-golang", "variables"},
+		{"https://api.boot.dev/v1/static/courses/slug/learn-golang", TargetCourse, "learn-golang", ""},
+		{"https://api.boot.dev/v1/static/courses/learn-golang", TargetCourse, "learn-golang", ""},
+		// tracks
+		{"https://www.boot.dev/tracks/backend", TargetTrack, "backend", ""},
+		{"https://www.boot.dev/tracks/backend-python-golang/?x=1", TargetTrack, "backend-python-golang", ""},
+		// bare slugs, which may be courses, course aliases or tracks
+		{"learn-golang", TargetSlug, "learn-golang", ""},
+		{"Backend", TargetSlug, "backend", ""},
+		{"learn-go", TargetSlug, "learn-go", ""},
+	}
+	for _, tt := range tests {
+		got, err := parseTarget(tt.in)
+		if err != nil {
+			t.Errorf("parseTarget(%q): unexpected error %v", tt.in, err)
+			continue
+		}
+		if got.Kind != tt.kind || got.ID != tt.id || got.Chapter != tt.chapter {
+			t.Errorf("parseTarget(%q) = %s %q #%q, want %s %q #%q", tt.in, got.Kind, got.ID, got.Chapter, tt.kind, tt.id, tt.chapter)
+		}
+	}
+}
+
+func TestParseTargetInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"   ",
+		"learn golang",
+		"learn_golang",
+		"-learn-golang",
+		"https://example.com/lessons/" + testUUID,
+		"https://www.boot.dev.example.com/lessons/" + testUUID,
+		"https://www.boot.dev/lessons/not-a-uuid",
+		"https://www.boot.dev/lessons/" + testUUID + "x",
+		"https://www.boot.dev/courses",
+		"https://www.boot.dev/courses/learn_golang",
+		"https://www.boot.dev/tracks/",
+		"https://www.boot.dev/blog/learn-golang",
+		"https://www.boot.dev/courses/learn-golang/extra",
+		"https://api.boot.dev/v1/static/quizzes/" + testUUID,
+		"https://www.boot.dev/%zz",
+	} {
+		if got, err := parseTarget(in); err == nil {
+			t.Errorf("parseTarget(%q) = %s %q, want an error", in, got.Kind, got.ID)
+		}
+	}
+}
+
+// useTestWorkspace points the workspace at a temporary directory whose cache
+// holds the given responses.
+func useTestWorkspace(t *testing.T, responses map[string]any) {
+	t.Helper()
+	old := workspace
+	workspace = t.TempDir()
+	t.Cleanup(func() { workspace = old })
+	for url, res := range responses {
+		body, err := json.Marshal(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeCache(url, body)
+	}
+}
+
+func TestTargetResolve(t *testing.T) {
+	var golang Response
+	golang.Course.Slug = "learn-golang"
+	golang.Course.SlugAliases = []string{"learn-go", "golang"}
+	useTestWorkspace(t, map[string]any{
+		TRACKS_URL:                  TracksResponse{{Slug: "backend"}},
+		COURSE_URL + "learn-golang": golang,
+	})
+
+	tests := []struct {
+		in   Target
+		want Target
+	}{
+		{Target{Kind: TargetSlug, ID: "backend"}, Target{Kind: TargetTrack, ID: "backend"}},
+		{Target{Kind: TargetSlug, ID: "learn-golang"}, Target{Kind: TargetCourse, ID: "learn-golang"}},
+		{Target{Kind: TargetSlug, ID: "learn-go"}, Target{Kind: TargetCourse, ID: "learn-golang"}},
+		{Target{Kind: TargetCourse, ID: "golang", Chapter: "chapter-2"}, Target{Kind: TargetCourse, ID: "learn-golang", Chapter: "chapter-2"}},
+		// not cached, left to the course API
+		{Target{Kind: TargetSlug, ID: "learn-rust"}, Target{Kind: TargetCourse, ID: "learn-rust"}},
+		{Target{Kind: TargetTrack, ID: "golang"}, Target{Kind: TargetTrack, ID: "golang"}},
+		{Target{Kind: TargetLesson, ID: testUUID}, Target{Kind: TargetLesson, ID: testUUID}},
+	}
+	for _, tt := range tests {
+		if got := tt.in.resolve(); got != tt.want {
+			t.Errorf("%+v.resolve() = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnchoredChapter(t *testing.T) {
+	m := Model{response: &Response{}, courseProgressResponse: &CourseProgressResponse{
+		Chapters: []Chapter{{UUID: "c1"}, {UUID: "c2"}, {UUID: "c3"}},
+	}}
+	course := `{"Chapters": [{"UUID": "c1", "Slug": "intro"}, {"UUID": "c2", "Slug": "variables"}, {"UUID": "c3", "Slug": "functions"}]}`
+	if err := json.Unmarshal([]byte(course), &m.response.Course); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		anchor string
+		want   int
+	}{
+		{"", -1},
+		{"chapter-1", 0},
+		{"Chapter-3", 2},
+		{"2", 1},
+		{"chapter-4", -1},
+		{"0", -1},
+		{"variables", 1},
+		{"c3", 2},
+		{"unknown", -1},
+	}
+	for _, tt := range tests {
+		m.chapterAnchor = tt.anchor
+		if got := m.anchoredChapter(); got != tt.want {
+			t.Errorf("anchoredChapter(%q) = %d, want %d", tt.anchor, got, tt.want)
+		}
+	}
+}
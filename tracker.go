@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleTimeout is the longest gap between two activities that still counts
+// as working on a lesson.
+const idleTimeout = 5 * time.Minute
+
+// activityTracker accumulates active time on the current lesson. Activity
+// is a key press or mouse event in the TUI, a state transition, or a file
+// in the lesson directory being modified while the editor is open.
+type activityTracker struct {
+	mu      sync.Mutex
+	uuid    string
+	dir     string
+	last    time.Time
+	lastMod time.Time
+	pending time.Duration
+	done    chan struct{}
+}
+
+var tracker = &activityTracker{}
+
+// switchTo saves the time spent on the previous lesson and starts tracking
+// uuid, whose files live in dir.
+func (t *activityTracker) switchTo(uuid, dir string) error {
+	if err := t.flush(); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done != nil {
+		close(t.done)
+	}
+	t.uuid = uuid
+	t.dir = dir
+	t.last = time.Now()
+	t.lastMod = latestModTime(dir)
+	t.done = make(chan struct{})
+	go t.watch(t.done)
+	return nil
+}
+
+func (t *activityTracker) touch(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.uuid == "" || at.Before(t.last) {
+		return
+	}
+	if gap := at.Sub(t.last); gap <= idleTimeout {
+		t.pending += gap
+	}
+	t.last = at
+}
+
+// watch polls the lesson directory, since the TUI receives no events while
+// an external editor has the terminal.
+func (t *activityTracker) watch(done chan struct{}) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			t.mu.Lock()
+			dir, lastMod := t.dir, t.lastMod
+			t.mu.Unlock()
+			if mod := latestModTime(dir); mod.After(lastMod) {
+				t.mu.Lock()
+				t.lastMod = mod
+				t.mu.Unlock()
+				t.touch(mod)
+			}
+		}
+	}
+}
+
+// flush adds the pending active time to the lesson's progress.
+func (t *activityTracker) flush() error {
+	t.mu.Lock()
+	uuid, pending := t.uuid, t.pending
+	t.pending = 0
+	t.mu.Unlock()
+	if uuid == "" || pending == 0 || progress == nil {
+		return nil
+	}
+	return progress.Update(uuid, func(l *LessonProgress) {
+		l.ActiveTime += pending
+	})
+}
+
+func (t *activityTracker) stop() error {
+	t.touch(time.Now())
+	err := t.flush()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done != nil {
+		close(t.done)
+		t.done = nil
+	}
+	t.uuid = ""
+	return err
+}
+
+// elapsed is the recorded plus not yet flushed active time on uuid.
+func (t *activityTracker) elapsed(uuid string) time.Duration {
+	var d time.Duration
+	if progress != nil {
+		d = progress.Lesson(uuid).ActiveTime
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.uuid == uuid {
+		d += t.pending
+	}
+	return d
+}
+
+func latestModTime(dir string) time.Time {
+	var latest time.Time
+	if dir == "" {
+		return latest
+	}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && path != dir && d.Name()[0] == '.' {
+			return filepath.SkipDir
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
+
+type trackerTickMsg struct{}
+
+// trackerTick redraws the lesson timer in the header periodically.
+func trackerTick() tea.Cmd {
+	return tea.Tick(30*time.Second, func(time.Time) tea.Msg { return trackerTickMsg{} })
+}
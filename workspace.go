@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// workspaceMarker is the directory marking the root of a workspace so it
+// can be found from any subdirectory.
+const workspaceMarker = ".bootdev-local"
+
+// workspace is the absolute root every lesson, state and .lib path is
+// resolved against.
+var workspace string
+
+// findWorkspace walks up from dir looking for a workspace marker.
+func findWorkspace(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, workspaceMarker)); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// setupWorkspace resolves the workspace root. In order of precedence: the
+// -workspace flag, given as override, a marker in the current directory or
+// one of its parents, workspace.root from the config, and finally the
+// current directory.
+func setupWorkspace(override string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	root := expandHome(override)
+	if root == "" {
+		if found, ok := findWorkspace(cwd); ok {
+			root = found
+		} else if cfg.Workspace.Root != "" {
+			root = cfg.Workspace.Root
+		} else {
+			root = cwd
+		}
+	}
+
+	if root, err = filepath.Abs(root); err != nil {
+		return err
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return fmt.Errorf("workspace %s is not a directory", root)
+	}
+	workspace = root
+	return nil
+}
+
+// markWorkspace creates the marker directory, which also holds the
+// workspace's local state, so later runs from subdirectories find it.
+func markWorkspace() error {
+	return os.MkdirAll(stateDir(), os.FileMode(cfg.Workspace.DirMode))
+}
+
+// stateDir is where bootdev-local keeps its own files for the workspace.
+func stateDir() string {
+	return workspacePath(workspaceMarker)
+}
+
+func workspacePath(elem ...string) string {
+	return filepath.Join(append([]string{workspace}, elem...)...)
+}